@@ -0,0 +1,360 @@
+// Package annotation keeps the user's notes and highlights on passages.
+//
+// They are kept in a JSON file keyed by references written out in full, so
+// that they belong to the passage rather than to a translation:
+//
+//	{
+//	  "version": 1,
+//	  "highlights": {
+//	    "Romans 8:28-30": {"color": "yellow", "created": "2026-10-19T07:30:00Z"}
+//	  },
+//	  "notes": {
+//	    "John 3:16": [{"id": 1, "text": "...", "created": "2026-10-19T07:30:00Z",
+//	                   "updated": "2026-10-19T07:30:00Z"}]
+//	  }
+//	}
+//
+// Highlights never overlap: highlighting verses takes them out of any
+// highlight they were already in, splitting it if need be, so each verse has
+// at most one colour. Notes may overlap freely.
+package annotation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/settings"
+)
+
+// Version is the version of the layout of the file
+const Version = 1
+
+// Colors are the colours verses can be highlighted in
+var Colors = []string{"yellow", "green", "blue", "pink", "cyan", "red"}
+
+// Store holds the notes and highlights
+type Store struct {
+	Version    int                  `json:"version"`
+	Highlights map[string]Highlight `json:"highlights"`
+	Notes      map[string][]Note    `json:"notes"`
+}
+
+// Highlight is the colour a passage is highlighted in
+type Highlight struct {
+	Color   string    `json:"color"`
+	Created time.Time `json:"created"`
+}
+
+// Note is a note on a passage. Its ID is unique within the store.
+type Note struct {
+	ID      int       `json:"id"`
+	Text    string    `json:"text"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Annotation is a note or highlight along with the passage it is on
+type Annotation struct {
+	Range     ref.Range
+	Note      *Note
+	Highlight *Highlight
+}
+
+// New returns an empty store
+func New() *Store {
+	return &Store{
+		Version:    Version,
+		Highlights: make(map[string]Highlight),
+		Notes:      make(map[string][]Note),
+	}
+}
+
+// Load reads the store at path. A missing file is an empty store.
+func Load(path string) (*Store, error) {
+	s := New()
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", path, err)
+	}
+	if s.Version > Version {
+		return nil, fmt.Errorf("%s was written by a newer version of bible (version %d, expected at most %d)", path, s.Version, Version)
+	}
+	s.Version = Version
+	if s.Highlights == nil {
+		s.Highlights = make(map[string]Highlight)
+	}
+	if s.Notes == nil {
+		s.Notes = make(map[string][]Note)
+	}
+
+	for key := range s.Highlights {
+		if _, err := ref.ParseRange(key); err != nil {
+			return nil, fmt.Errorf("Error reading %s: %s", path, err)
+		}
+	}
+	for key := range s.Notes {
+		if _, err := ref.ParseRange(key); err != nil {
+			return nil, fmt.Errorf("Error reading %s: %s", path, err)
+		}
+	}
+
+	return s, nil
+}
+
+// Save writes the store to path, replacing the file in one step
+func (s *Store) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return settings.WriteData(path, append(data, '\n'))
+}
+
+// Update changes the store at path as one transaction: it takes the lock on
+// the file, reads it, lets f change it and writes it back. Nothing is written
+// if f returns an error.
+func Update(path string, f func(s *Store) error) error {
+	lock, err := settings.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	s, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := f(s); err != nil {
+		return err
+	}
+
+	return s.Save(path)
+}
+
+// CheckColor checks that color is one of Colors
+func CheckColor(color string) error {
+	for _, c := range Colors {
+		if c == color {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unknown color %q, expected one of %q", color, Colors)
+}
+
+// Highlight highlights the verses of r in color, taking them out of any
+// other highlight. An empty color takes the highlight off them.
+func (s *Store) Highlight(r *ref.Range, color string, now time.Time) error {
+	if color != "" {
+		if err := CheckColor(color); err != nil {
+			return err
+		}
+	}
+
+	first, last := span(r)
+	for key, h := range s.Highlights {
+		kr, _ := ref.ParseRange(key)
+		a, b := span(kr)
+		if b < first || a > last {
+			continue
+		}
+
+		// Keep the parts either side of r
+		delete(s.Highlights, key)
+		if a < first {
+			s.Highlights[rangeKey(a, first-1)] = h
+		}
+		if b > last {
+			s.Highlights[rangeKey(last+1, b)] = h
+		}
+	}
+
+	if color != "" {
+		s.Highlights[r.String()] = Highlight{Color: color, Created: now}
+	}
+
+	return nil
+}
+
+// AddNote adds a note on r and returns its ID
+func (s *Store) AddNote(r *ref.Range, text string, now time.Time) int {
+	id := 1
+	for _, notes := range s.Notes {
+		for _, n := range notes {
+			if n.ID >= id {
+				id = n.ID + 1
+			}
+		}
+	}
+
+	key := r.String()
+	s.Notes[key] = append(s.Notes[key], Note{ID: id, Text: text, Created: now, Updated: now})
+	return id
+}
+
+// Note returns the note with the given ID
+func (s *Store) Note(id int) (*Note, error) {
+	for key, notes := range s.Notes {
+		for i := range notes {
+			if notes[i].ID == id {
+				return &s.Notes[key][i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("You don't have a note numbered %d", id)
+}
+
+// EditNote replaces the text of the note with the given ID
+func (s *Store) EditNote(id int, text string, now time.Time) error {
+	n, err := s.Note(id)
+	if err != nil {
+		return err
+	}
+
+	n.Text, n.Updated = text, now
+	return nil
+}
+
+// DeleteNote deletes the note with the given ID
+func (s *Store) DeleteNote(id int) error {
+	for key, notes := range s.Notes {
+		for i := range notes {
+			if notes[i].ID != id {
+				continue
+			}
+
+			s.Notes[key] = append(notes[:i], notes[i+1:]...)
+			if len(s.Notes[key]) == 0 {
+				delete(s.Notes, key)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("You don't have a note numbered %d", id)
+}
+
+// List returns the annotations overlapping r, or all of them if r is nil,
+// in the order their passages come in the Bible, shorter ones first where
+// they start together. Notes on the same verses come in the order they were
+// added, after their highlight.
+func (s *Store) List(r *ref.Range) []Annotation {
+	var list []Annotation
+	for key, h := range s.Highlights {
+		if kr, ok := overlapping(key, r); ok {
+			h := h
+			list = append(list, Annotation{Range: *kr, Highlight: &h})
+		}
+	}
+	for key, notes := range s.Notes {
+		if kr, ok := overlapping(key, r); ok {
+			for i := range notes {
+				list = append(list, Annotation{Range: *kr, Note: &notes[i]})
+			}
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		aFirst, aLast := span(&a.Range)
+		bFirst, bLast := span(&b.Range)
+		if aFirst != bFirst {
+			return aFirst < bFirst
+		}
+		if aLast != bLast {
+			return aLast < bLast
+		}
+		if (a.Highlight != nil) != (b.Highlight != nil) {
+			return a.Highlight != nil
+		}
+		return a.Note != nil && b.Note != nil && a.Note.ID < b.Note.ID
+	})
+
+	return list
+}
+
+// Apply marks the verses of p with their highlights, and gives each note on
+// them to the last of its verses in p, where it is marked
+func (s *Store) Apply(p *passage.Passage) {
+	for i := range p.Verses {
+		p.Verses[i].Highlight, p.Verses[i].Notes = "", nil
+	}
+	if len(p.Verses) == 0 {
+		return
+	}
+
+	r := &ref.Range{Start: p.Verses[0].Ref, End: p.Verses[len(p.Verses)-1].Ref}
+	for _, a := range s.List(r) {
+		if a.Highlight != nil {
+			for i := range p.Verses {
+				if a.Range.Contains(&p.Verses[i].Ref) {
+					p.Verses[i].Highlight = a.Highlight.Color
+				}
+			}
+			continue
+		}
+
+		for i := len(p.Verses) - 1; i >= 0; i-- {
+			if a.Range.Contains(&p.Verses[i].Ref) {
+				p.Verses[i].Notes = append(p.Verses[i].Notes, passage.Note{ID: a.Note.ID, Ref: a.Range.String(), Text: a.Note.Text})
+				break
+			}
+		}
+	}
+}
+
+// overlapping parses a key and reports whether it overlaps r. Every key
+// overlaps a nil range.
+func overlapping(key string, r *ref.Range) (*ref.Range, bool) {
+	kr, err := ref.ParseRange(key)
+	if err != nil {
+		return nil, false
+	}
+	if r == nil {
+		return kr, true
+	}
+
+	a, b := span(kr)
+	first, last := span(r)
+	return kr, a <= last && b >= first
+}
+
+// span returns the positions of the first and last verses of r in the Bible
+func span(r *ref.Range) (first, last int) {
+	v := r.Verses()
+	return ref.Index(&v.Start), ref.Index(&v.End)
+}
+
+// rangeKey returns the key for the verses from position first to last
+func rangeKey(first, last int) string {
+	return (&ref.Range{Start: *ref.VerseAt(first), End: *ref.VerseAt(last)}).String()
+}
+
+// Summary returns the first line of a note's text, cut to width characters
+func (n *Note) Summary(width int) string {
+	text := strings.TrimSpace(n.Text)
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[:i] + "…"
+	}
+	if r := []rune(text); width > 1 && len(r) > width {
+		text = string(r[:width-1]) + "…"
+	}
+
+	return text
+}
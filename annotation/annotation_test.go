@@ -0,0 +1,178 @@
+package annotation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+var now = time.Date(2026, 10, 19, 7, 30, 0, 0, time.UTC)
+
+func rng(t *testing.T, s string) *ref.Range {
+	r, err := ref.ParseRange(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+// highlights lists the highlights as "ref color", in order
+func highlights(s *Store) string {
+	var list []string
+	for _, a := range s.List(nil) {
+		if a.Highlight != nil {
+			list = append(list, a.Range.String()+" "+a.Highlight.Color)
+		}
+	}
+
+	return strings.Join(list, "; ")
+}
+
+func TestHighlight(t *testing.T) {
+	cases := []struct {
+		r     string
+		color string
+		want  string
+	}{
+		{"Romans 8:28-30", "yellow", "Romans 8:28-30 yellow"},
+		{"Romans 8:29", "green", "Romans 8:28 yellow; Romans 8:29 green; Romans 8:30 yellow"},
+		{"Romans 8:30-32", "blue", "Romans 8:28 yellow; Romans 8:29 green; Romans 8:30-32 blue"},
+		{"Romans 8:28-29", "", "Romans 8:30-32 blue"},
+		{"Romans 8", "pink", "Romans 8 pink"},
+		{"Romans 8:2-38", "", "Romans 8:1 pink; Romans 8:39 pink"},
+		{"Romans 7:25-8:1", "red", "Romans 7:25-8:1 red; Romans 8:39 pink"},
+	}
+
+	s := New()
+	for _, c := range cases {
+		if err := s.Highlight(rng(t, c.r), c.color, now); err != nil {
+			t.Fatal(err)
+		}
+		if got := highlights(s); got != c.want {
+			t.Errorf("Highlight(%v, %q) -> %v, wanted %v", c.r, c.color, got, c.want)
+		}
+	}
+
+	if err := s.Highlight(rng(t, "John 1"), "mauve", now); err == nil {
+		t.Errorf("expected an error for an unknown colour")
+	}
+}
+
+func TestNotes(t *testing.T) {
+	s := New()
+	first := s.AddNote(rng(t, "John 3:16"), "For God so loved", now)
+	second := s.AddNote(rng(t, "John 3:1-21"), "Nicodemus", now)
+	third := s.AddNote(rng(t, "John 3:16"), "Luther's gospel in miniature", now)
+	if first != 1 || second != 2 || third != 3 {
+		t.Errorf("AddNote -> IDs %d, %d, %d", first, second, third)
+	}
+
+	later := now.Add(time.Hour)
+	if err := s.EditNote(2, "Nicodemus comes by night", later); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteNote(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteNote(1); err == nil {
+		t.Errorf("expected an error deleting a note twice")
+	}
+	if err := s.EditNote(9, "", later); err == nil {
+		t.Errorf("expected an error editing a missing note")
+	}
+
+	list := s.List(rng(t, "John 3:16-17"))
+	if len(list) != 2 || list[0].Note.ID != 2 || list[1].Note.ID != 3 {
+		t.Fatalf("List -> %+v", list)
+	}
+	if list[0].Note.Text != "Nicodemus comes by night" || !list[0].Note.Updated.Equal(later) || !list[0].Note.Created.Equal(now) {
+		t.Errorf("edited note -> %+v", list[0].Note)
+	}
+	if list := s.List(rng(t, "John 4")); len(list) != 0 {
+		t.Errorf("List(John 4) -> %+v", list)
+	}
+
+	// IDs aren't reused
+	if id := s.AddNote(rng(t, "John 1:1"), "", now); id != 4 {
+		t.Errorf("AddNote after deleting -> %d, wanted 4", id)
+	}
+}
+
+func TestApply(t *testing.T) {
+	s := New()
+	s.Highlight(rng(t, "Romans 8:28-29"), "yellow", now)
+	s.AddNote(rng(t, "Romans 8:26-28"), "Prayer", now)
+	s.AddNote(rng(t, "Romans 8:29-39"), "Golden chain", now)
+
+	p := &passage.Passage{}
+	for v := 27; v <= 30; v++ {
+		p.Verses = append(p.Verses, passage.Verse{Ref: *ref.New(ref.Romans, 8, v)})
+	}
+	s.Apply(p)
+
+	var got []string
+	for _, v := range p.Verses {
+		var ids []string
+		for _, n := range v.Notes {
+			ids = append(ids, n.Ref)
+		}
+		sort.Strings(ids)
+		got = append(got, v.Highlight+"/"+strings.Join(ids, ","))
+	}
+
+	want := "/, yellow/Romans 8:26-28, yellow/, /Romans 8:29-39"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("Apply -> %v, wanted %v", strings.Join(got, ", "), want)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bible-annotation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "annotations.json")
+	if s, err := Load(path); err != nil || len(s.List(nil)) != 0 {
+		t.Errorf("Load(missing) -> %v, %v", s, err)
+	}
+
+	err = Update(path, func(s *Store) error {
+		s.AddNote(rng(t, "Ps 23"), "The shepherd", now)
+		return s.Highlight(rng(t, "Ps 23:1"), "green", now)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if !strings.Contains(string(data), `"Psalm 23:1": {`) || !strings.Contains(string(data), `"Psalm 23": [`) {
+		t.Errorf("file isn't keyed by references:\n%s", data)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := s.List(nil); len(list) != 2 || list[0].Highlight == nil || list[1].Note.Text != "The shepherd" {
+		t.Errorf("Load -> %+v", list)
+	}
+
+	ioutil.WriteFile(path, []byte(`{"version": 2}`), 0600)
+	if _, err := Load(path); err == nil {
+		t.Errorf("expected an error for a newer version")
+	}
+	ioutil.WriteFile(path, []byte(`{"version": 1, "notes": {"not a reference": []}}`), 0600)
+	if _, err := Load(path); err == nil {
+		t.Errorf("expected an error for a bad reference")
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dtjm/bible/annotation"
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/settings"
+)
+
+// annotationsFile returns the path of the notes and highlights
+func annotationsFile() string {
+	return filepath.Join(settings.DataDir(), "annotations.json")
+}
+
+// annotate marks the passages with the notes and highlights on them. Failing
+// to read them is logged but doesn't stop the passages being read.
+func annotate(passages []*passage.Passage) {
+	s, err := annotation.Load(annotationsFile())
+	if err != nil {
+		log.Printf("Error reading notes: %s", err)
+		return
+	}
+
+	for _, p := range passages {
+		s.Apply(p)
+	}
+}
+
+// printAnnotations lists notes and highlights, a line each: a note's number,
+// passage and the first line of its text, or a highlight's passage and colour
+func (c *config) printAnnotations(w io.Writer, list []annotation.Annotation) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	for _, a := range list {
+		r := a.Range.Format(c.RefStyle)
+		if a.Note != nil {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", a.Note.ID, r, a.Note.Summary(60))
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\n", r, a.Highlight.Color)
+		}
+	}
+	tw.Flush()
+
+	return writeColumns(w, buf.String())
+}
+
+// notesOnly returns the notes in list, leaving out the highlights
+func notesOnly(list []annotation.Annotation) []annotation.Annotation {
+	var notes []annotation.Annotation
+	for _, a := range list {
+		if a.Note != nil {
+			notes = append(notes, a)
+		}
+	}
+
+	return notes
+}
+
+// editText opens text in $EDITOR, or vi, and returns it as it was saved
+func editText(text string) (string, error) {
+	f, err := ioutil.TempFile("", "bible-note-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(text)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// $EDITOR may have arguments, e.g. "code --wait"
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("Error running %s: %s", editor, err)
+	}
+
+	data, err := ioutil.ReadFile(f.Name())
+	return strings.TrimSpace(string(data)), err
+}
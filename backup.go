@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dtjm/bible/annotation"
+	"github.com/dtjm/bible/backup"
+	"github.com/dtjm/bible/history"
+	"github.com/dtjm/bible/memorize"
+	"github.com/dtjm/bible/plan"
+	"github.com/dtjm/bible/settings"
+)
+
+// collectBackup gathers the bookmarks and plan progress from s, the notes and
+// highlights from st and the cards from d, along with the history and the
+// generated plans, into a backup
+func collectBackup(s *settings.Config, st *annotation.Store, d *memorize.Deck, now time.Time) (*backup.Backup, error) {
+	b := backup.New(now)
+	for name, bm := range s.Bookmarks {
+		b.Bookmarks[name] = bm
+	}
+	for name, pr := range s.Plans {
+		b.Plans[name] = pr
+	}
+	for key, h := range st.Highlights {
+		b.Highlights[key] = h
+	}
+	for key, notes := range st.Notes {
+		b.Notes[key] = append([]annotation.Note(nil), notes...)
+	}
+	for key, c := range d.Cards {
+		b.Memory[key] = c
+	}
+
+	var err error
+	if b.History, err = history.Load(historyFile()); err != nil {
+		return nil, err
+	}
+
+	files, _ := filepath.Glob(filepath.Join(plansDir(), "*.toml"))
+	for _, f := range files {
+		p, err := readPlanFile(f)
+		if err != nil {
+			return nil, err
+		}
+		b.Generated[p.Name] = p
+	}
+
+	return b, nil
+}
+
+// exportBackup gathers everything into a backup
+func (c *config) exportBackup(now time.Time) (*backup.Backup, error) {
+	st, err := annotation.Load(annotationsFile())
+	if err != nil {
+		return nil, err
+	}
+	d, err := memorize.Load(memoryFile())
+	if err != nil {
+		return nil, err
+	}
+
+	return collectBackup(c.Config, st, d, now)
+}
+
+// importBackup merges in into everything there is by strategy, one of
+// backup.Strategies, and returns what there is afterwards. The history is
+// locked throughout, so that nothing read meanwhile is lost, and the config,
+// notes and cards are each locked while they are changed. The history and
+// generated plans are only written once the others have been saved.
+func (c *config) importBackup(in *backup.Backup, strategy string, now time.Time) (*backup.Backup, error) {
+	if err := backup.CheckStrategy(strategy); err != nil {
+		return nil, err
+	}
+
+	lock, err := settings.LockFile(historyFile())
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	var merged *backup.Backup
+	err = c.Update(func(s *settings.Config) error {
+		return annotation.Update(annotationsFile(), func(st *annotation.Store) error {
+			return memorize.Update(memoryFile(), func(d *memorize.Deck) error {
+				b, err := collectBackup(s, st, d, now)
+				if err != nil {
+					return err
+				}
+				if err := b.Merge(in, strategy); err != nil {
+					return err
+				}
+
+				s.Bookmarks, s.Plans = b.Bookmarks, b.Plans
+				st.Highlights, st.Notes = b.Highlights, b.Notes
+				d.Cards = b.Memory
+				merged = b
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := history.Save(historyFile(), merged.History); err != nil {
+		return nil, err
+	}
+	if err := savePlans(merged.Generated, strategy == "replace"); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// savePlans writes the generated plans to plansDir, and with prune removes
+// any others there
+func savePlans(plans map[string]*plan.Plan, prune bool) error {
+	for name, p := range plans {
+		var buf bytes.Buffer
+		if err := p.Write(&buf, "toml"); err != nil {
+			return err
+		}
+		if err := settings.WriteData(filepath.Join(plansDir(), strings.ToLower(name)+".toml"), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if prune {
+		files, _ := filepath.Glob(filepath.Join(plansDir(), "*.toml"))
+		for _, f := range files {
+			if _, ok := plans[strings.TrimSuffix(filepath.Base(f), ".toml")]; !ok {
+				if err := os.Remove(f); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+// Package backup gathers bookmarks, history, notes and highlights, plan
+// progress and the passages being memorised into one file, to keep or to
+// carry to another machine, and merges such a file into what is there.
+//
+// The file is JSON with a version. Every reference in it is written out in
+// full, as ref.Range.String writes it, so that it reads the same whatever
+// the reference style or locale it was exported with:
+//
+//	{
+//	  "version": 1,
+//	  "exported": "2026-10-19T07:30:00Z",
+//	  "bookmarks": {"next": {"ref": "John 4", "created": "...", "updated": "..."}},
+//	  "history": [{"time": "...", "command": "read", "ref": "John 3", ...}],
+//	  "highlights": {"Romans 8:28-30": {"color": "yellow", "created": "..."}},
+//	  "notes": {"John 3:16": [{"id": 1, "text": "...", ...}]},
+//	  "plans": {"mcheyne": {"start": "2026-01-01T00:00:00Z", "done": [1, 2]}},
+//	  "generated_plans": {"lent": {"name": "lent", "title": "...", "days": [...]}},
+//	  "memory": {"Psalm 119:11": {"translation": "ESV", "text": "...", ...}}
+//	}
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/dtjm/bible/annotation"
+	"github.com/dtjm/bible/history"
+	"github.com/dtjm/bible/memorize"
+	"github.com/dtjm/bible/plan"
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/settings"
+)
+
+// Version is the version of the layout of the file
+const Version = 1
+
+// Backup is everything the user has built up
+type Backup struct {
+	Version  int       `json:"version"`
+	Exported time.Time `json:"exported"`
+
+	Bookmarks  map[string]settings.Bookmark    `json:"bookmarks"`
+	History    []history.Entry                 `json:"history"`
+	Highlights map[string]annotation.Highlight `json:"highlights"`
+	Notes      map[string][]annotation.Note    `json:"notes"`
+	Plans      map[string]plan.Progress        `json:"plans"`
+
+	// Generated holds the plans made with plan generate, which some of
+	// Plans may be progress through
+	Generated map[string]*plan.Plan `json:"generated_plans"`
+
+	Memory map[string]memorize.Card `json:"memory"`
+}
+
+// New returns an empty backup made at now
+func New(now time.Time) *Backup {
+	b := &Backup{Version: Version, Exported: now}
+	b.init()
+	return b
+}
+
+// init makes the maps a backup read from a file may be missing
+func (b *Backup) init() {
+	if b.Bookmarks == nil {
+		b.Bookmarks = make(map[string]settings.Bookmark)
+	}
+	if b.Highlights == nil {
+		b.Highlights = make(map[string]annotation.Highlight)
+	}
+	if b.Notes == nil {
+		b.Notes = make(map[string][]annotation.Note)
+	}
+	if b.Plans == nil {
+		b.Plans = make(map[string]plan.Progress)
+	}
+	if b.Generated == nil {
+		b.Generated = make(map[string]*plan.Plan)
+	}
+	if b.Memory == nil {
+		b.Memory = make(map[string]memorize.Card)
+	}
+	if b.History == nil {
+		b.History = []history.Entry{}
+	}
+}
+
+// Read reads a backup written by Write
+func Read(r io.Reader) (*Backup, error) {
+	var b Backup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("Error reading backup: %s", err)
+	}
+	if b.Version == 0 {
+		return nil, fmt.Errorf("Error reading backup: it has no version; is it a file made with bible export?")
+	}
+	if b.Version > Version {
+		return nil, fmt.Errorf("The backup was written by a newer version of bible (version %d, expected at most %d)", b.Version, Version)
+	}
+
+	b.Version = Version
+	b.init()
+	if err := b.Normalize(); err != nil {
+		return nil, fmt.Errorf("Error reading backup: %s", err)
+	}
+
+	return &b, nil
+}
+
+// Write writes the backup as indented JSON
+func (b *Backup) Write(w io.Writer) error {
+	if err := b.Normalize(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Normalize writes every reference in the backup out in full, so that the
+// same passage is always written the same way, and sorts the history by
+// time. It is an error if a reference can't be parsed.
+func (b *Backup) Normalize() error {
+	for name, bm := range b.Bookmarks {
+		for _, s := range []*string{&bm.Ref, &bm.Previous, &bm.Loop} {
+			full, err := canonical(*s)
+			if err != nil {
+				return fmt.Errorf("bookmark %s: %s", name, err)
+			}
+			*s = full
+		}
+		b.Bookmarks[name] = bm
+	}
+
+	highlights := make(map[string]annotation.Highlight)
+	for key, h := range b.Highlights {
+		full, err := canonical(key)
+		if err != nil {
+			return err
+		}
+		highlights[full] = h
+	}
+	b.Highlights = highlights
+
+	notes := make(map[string][]annotation.Note)
+	for key, ns := range b.Notes {
+		full, err := canonical(key)
+		if err != nil {
+			return err
+		}
+		notes[full] = append(notes[full], ns...)
+	}
+	b.Notes = notes
+
+	memory := make(map[string]memorize.Card)
+	for key, c := range b.Memory {
+		full, err := canonical(key)
+		if err != nil {
+			return err
+		}
+		memory[full] = c
+	}
+	b.Memory = memory
+
+	generated := make(map[string]*plan.Plan)
+	for name, p := range b.Generated {
+		if p == nil {
+			return fmt.Errorf("generated plan %s is empty", name)
+		}
+		generated[p.Name] = p
+	}
+	b.Generated = generated
+
+	b.init()
+	sort.SliceStable(b.History, func(i, j int) bool { return b.History[i].Time.Before(b.History[j].Time) })
+	return nil
+}
+
+// canonical returns a reference written out in full, or "" for ""
+func canonical(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	r, err := ref.ParseRange(s)
+	if err != nil {
+		return "", err
+	}
+
+	return r.String(), nil
+}
+
+// Summary counts what is in the backup, e.g. "3 bookmarks, 120 history
+// entries, 2 highlights, 4 notes, 1 plan, 0 generated plans, 2 memorized
+// passages"
+func (b *Backup) Summary() string {
+	notes := 0
+	for _, ns := range b.Notes {
+		notes += len(ns)
+	}
+
+	return fmt.Sprintf("%s, %s, %s, %s, %s, %s, %s",
+		count(len(b.Bookmarks), "bookmark", "bookmarks"),
+		count(len(b.History), "history entry", "history entries"),
+		count(len(b.Highlights), "highlight", "highlights"),
+		count(notes, "note", "notes"),
+		count(len(b.Plans), "plan", "plans"),
+		count(len(b.Generated), "generated plan", "generated plans"),
+		count(len(b.Memory), "memorized passage", "memorized passages"))
+}
+
+func count(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+
+	return fmt.Sprintf("%d %s", n, many)
+}
@@ -0,0 +1,182 @@
+package backup
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtjm/bible/annotation"
+	"github.com/dtjm/bible/history"
+	"github.com/dtjm/bible/memorize"
+	"github.com/dtjm/bible/plan"
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/settings"
+)
+
+var now = time.Date(2026, 10, 19, 7, 30, 0, 0, time.UTC)
+
+func at(hours int) time.Time {
+	return now.Add(time.Duration(hours) * time.Hour)
+}
+
+func entry(t *testing.T, when time.Time, refString string) history.Entry {
+	r, err := ref.ParseRange(refString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return history.Entry{Time: when, Command: "read", Range: *r, Translation: "ESV"}
+}
+
+// local and remote are the same user's things on two machines
+func local(t *testing.T) *Backup {
+	b := New(now)
+	b.Bookmarks["next"] = settings.Bookmark{Ref: "John 4", Created: at(-48), Updated: at(-2)}
+	b.Bookmarks["fav"] = settings.Bookmark{Ref: "Rom 8:28", Created: at(-48), Updated: at(-48)}
+	b.History = []history.Entry{entry(t, at(-3), "John 2"), entry(t, at(-2), "John 3")}
+	b.Highlights["Romans 8:28-30"] = annotation.Highlight{Color: "yellow", Created: at(-10)}
+	b.Notes["John 3:16"] = []annotation.Note{{ID: 1, Text: "mine", Created: at(-5), Updated: at(-5)}}
+	b.Plans["mcheyne"] = plan.Progress{Start: plan.Date(at(-72)), Done: []int{1, 2}}
+	b.Memory["Psalm 119:11"] = memorize.NewCard("ESV", "I have stored up your word", at(-30))
+	return b
+}
+
+func remote(t *testing.T) *Backup {
+	b := New(now)
+	b.Bookmarks["next"] = settings.Bookmark{Ref: "John 5", Created: at(-48), Updated: at(-1)}
+	b.Bookmarks["work"] = settings.Bookmark{Ref: "Col 3:23", Created: at(-20), Updated: at(-20)}
+	b.History = []history.Entry{entry(t, at(-3), "John 2"), entry(t, at(-1), "John 4")}
+	b.Highlights["Romans 8:30-31"] = annotation.Highlight{Color: "green", Created: at(-4)}
+	b.Notes["John 3:16"] = []annotation.Note{
+		{ID: 1, Text: "mine, edited", Created: at(-5), Updated: at(-1)},
+		{ID: 2, Text: "theirs", Created: at(-3), Updated: at(-3)},
+	}
+	b.Plans["mcheyne"] = plan.Progress{Start: plan.Date(at(-72)), Done: []int{3}}
+	card := memorize.NewCard("ESV", "I have stored up your word", at(-30))
+	card.Review(5, at(-1))
+	b.Memory["Psalm 119:11"] = card
+	return b
+}
+
+func highlights(b *Backup) string {
+	var hs []string
+	for key, h := range b.Highlights {
+		hs = append(hs, key+" "+h.Color)
+	}
+	sort.Strings(hs)
+	return strings.Join(hs, "; ")
+}
+
+func notes(b *Backup) string {
+	var ns []string
+	for _, n := range b.Notes["John 3:16"] {
+		ns = append(ns, string(rune('0'+n.ID))+" "+n.Text)
+	}
+	return strings.Join(ns, "; ")
+}
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		strategy   string
+		next       string
+		bookmarks  int
+		history    int
+		highlights string
+		notes      string
+		done       []int
+		reps       int
+	}{
+		{"union", "John 4", 3, 3, "Romans 8:28-30 yellow", "1 mine; 2 theirs", []int{1, 2, 3}, 0},
+		{"newest", "John 5", 3, 3, "Romans 8:28-29 yellow; Romans 8:30-31 green", "1 mine, edited; 2 theirs", []int{1, 2, 3}, 1},
+		{"replace", "John 5", 2, 2, "Romans 8:30-31 green", "1 mine, edited; 2 theirs", []int{3}, 1},
+	}
+
+	for _, c := range cases {
+		b := local(t)
+		if err := b.Merge(remote(t), c.strategy); err != nil {
+			t.Fatalf("%s: %s", c.strategy, err)
+		}
+
+		if got := b.Bookmarks["next"].Ref; got != c.next {
+			t.Errorf("%s: next -> %s, wanted %s", c.strategy, got, c.next)
+		}
+		if len(b.Bookmarks) != c.bookmarks {
+			t.Errorf("%s: %d bookmarks, wanted %d", c.strategy, len(b.Bookmarks), c.bookmarks)
+		}
+		if len(b.History) != c.history {
+			t.Errorf("%s: %d history entries, wanted %d", c.strategy, len(b.History), c.history)
+		}
+		for i := 1; i < len(b.History); i++ {
+			if b.History[i].Time.Before(b.History[i-1].Time) {
+				t.Errorf("%s: history out of order", c.strategy)
+			}
+		}
+		if got := highlights(b); got != c.highlights {
+			t.Errorf("%s: highlights -> %s, wanted %s", c.strategy, got, c.highlights)
+		}
+		if got := notes(b); got != c.notes {
+			t.Errorf("%s: notes -> %s, wanted %s", c.strategy, got, c.notes)
+		}
+		if got := b.Plans["mcheyne"].Done; !equal(got, c.done) {
+			t.Errorf("%s: done -> %v, wanted %v", c.strategy, got, c.done)
+		}
+		if got := b.Memory["Psalm 119:11"].Reps; got != c.reps {
+			t.Errorf("%s: reps -> %d, wanted %d", c.strategy, got, c.reps)
+		}
+	}
+
+	if err := New(now).Merge(New(now), "both"); err == nil {
+		t.Errorf("expected an error for an unknown strategy")
+	}
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReadWrite(t *testing.T) {
+	b := local(t)
+	b.Generated["lent"] = &plan.Plan{Name: "lent", Title: "Paul over Lent", Days: []plan.Day{{entry(t, now, "Rom 1-2").Range}}}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, `"ref": "Romans 8:28"`) || !strings.Contains(out, `"Romans 1-2"`) {
+		t.Errorf("Write didn't write references out in full:\n%s", out)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Summary() != b.Summary() {
+		t.Errorf("Read -> %s, wanted %s", got.Summary(), b.Summary())
+	}
+	want := "2 bookmarks, 2 history entries, 1 highlight, 1 note, 1 plan, 1 generated plan, 1 memorized passage"
+	if got.Summary() != want {
+		t.Errorf("Summary -> %s, wanted %s", got.Summary(), want)
+	}
+	if p := got.Generated["lent"]; p == nil || p.Title != "Paul over Lent" || p.Days[0].String() != "Romans 1-2" {
+		t.Errorf("Read generated plan -> %+v", p)
+	}
+	if !got.Notes["John 3:16"][0].Created.Equal(at(-5)) {
+		t.Errorf("Read note created -> %s", got.Notes["John 3:16"][0].Created)
+	}
+
+	for _, s := range []string{`{}`, `{"version": 2}`, `{"version": 1, "memory": {"not a reference": {}}}`} {
+		if _, err := Read(strings.NewReader(s)); err == nil {
+			t.Errorf("expected an error reading %s", s)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dtjm/bible/annotation"
+	"github.com/dtjm/bible/history"
+	"github.com/dtjm/bible/memorize"
+	"github.com/dtjm/bible/ref"
+)
+
+// Strategies are the ways a backup can be merged into what is there:
+//
+//   - union keeps everything from both, and where both have the same
+//     bookmark, highlighted verse, note or card, keeps the one already there
+//   - newest keeps everything from both too, but where both have the same
+//     thing keeps whichever was changed most recently
+//   - replace throws away what is there and puts the backup in its place
+//
+// History is never in conflict, so union and newest both keep every entry
+// from either, once.
+var Strategies = []string{"union", "newest", "replace"}
+
+// CheckStrategy checks that strategy is one of Strategies
+func CheckStrategy(strategy string) error {
+	for _, s := range Strategies {
+		if s == strategy {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unknown strategy %q, expected one of %q", strategy, Strategies)
+}
+
+// Merge merges in into b by strategy, one of Strategies
+func (b *Backup) Merge(in *Backup, strategy string) error {
+	if err := CheckStrategy(strategy); err != nil {
+		return err
+	}
+
+	if strategy == "replace" {
+		exported := b.Exported
+		*b = *in
+		b.Exported = exported
+		return nil
+	}
+
+	newest := strategy == "newest"
+	for name, bm := range in.Bookmarks {
+		mine, ok := b.Bookmarks[name]
+		if !ok || newest && changed(bm.Updated, bm.Created).After(changed(mine.Updated, mine.Created)) {
+			b.Bookmarks[name] = bm
+		}
+	}
+
+	b.mergeHistory(in.History)
+	if err := b.mergeHighlights(in.Highlights, newest); err != nil {
+		return err
+	}
+	b.mergeNotes(in.Notes, newest)
+
+	for name, pr := range in.Plans {
+		mine, ok := b.Plans[name]
+		switch {
+		case !ok || newest && pr.Start.After(mine.Start):
+			b.Plans[name] = pr
+		case pr.Start.Equal(mine.Start):
+			for _, day := range pr.Done {
+				mine.MarkDone(day, true)
+			}
+			b.Plans[name] = mine
+		}
+	}
+
+	// Generated plans have no times to compare, so the ones already there
+	// are kept
+	for name, p := range in.Generated {
+		if _, ok := b.Generated[name]; !ok {
+			b.Generated[name] = p
+		}
+	}
+
+	for key, c := range in.Memory {
+		mine, ok := b.Memory[key]
+		if !ok || newest && reviewed(&c).After(reviewed(&mine)) {
+			b.Memory[key] = c
+		}
+	}
+
+	return nil
+}
+
+// mergeHistory adds the entries of in that aren't in b's history already,
+// keeping the history in order
+func (b *Backup) mergeHistory(in []history.Entry) {
+	type key struct {
+		time    int64
+		command string
+		ref     string
+	}
+	seen := make(map[key]bool)
+	for _, e := range b.History {
+		seen[key{e.Time.UnixNano(), e.Command, e.Range.String()}] = true
+	}
+
+	for _, e := range in {
+		k := key{e.Time.UnixNano(), e.Command, e.Range.String()}
+		if !seen[k] {
+			seen[k] = true
+			b.History = append(b.History, e)
+		}
+	}
+
+	sort.SliceStable(b.History, func(i, j int) bool { return b.History[i].Time.Before(b.History[j].Time) })
+}
+
+// mergeHighlights adds the highlights of in, oldest first. Since highlights
+// can't overlap, one that overlaps a highlight in b is left out, unless
+// newest is set and it was made after every highlight it overlaps, which it
+// then takes the verses of.
+func (b *Backup) mergeHighlights(in map[string]annotation.Highlight, newest bool) error {
+	keys := make([]string, 0, len(in))
+	for key := range in {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !in[keys[i]].Created.Equal(in[keys[j]].Created) {
+			return in[keys[i]].Created.Before(in[keys[j]].Created)
+		}
+		return keys[i] < keys[j]
+	})
+
+	s := &annotation.Store{Highlights: b.Highlights}
+	for _, key := range keys {
+		h := in[key]
+		if err := annotation.CheckColor(h.Color); err != nil {
+			return err
+		}
+		r, err := ref.ParseRange(key)
+		if err != nil {
+			return err
+		}
+
+		keep := true
+		for _, a := range s.List(r) {
+			if a.Highlight != nil && (!newest || !h.Created.After(a.Highlight.Created)) {
+				keep = false
+			}
+		}
+		if !keep {
+			continue
+		}
+		if err := s.Highlight(r, h.Color, h.Created); err != nil {
+			return err
+		}
+	}
+
+	b.Highlights = s.Highlights
+	return nil
+}
+
+// mergeNotes adds the notes of in that aren't in b, with new IDs. A note is
+// the same as one in b if it is on the same passage and was made at the same
+// time; with newest, the text that was edited last is kept.
+func (b *Backup) mergeNotes(in map[string][]annotation.Note, newest bool) {
+	id := 1
+	for _, notes := range b.Notes {
+		for _, n := range notes {
+			if n.ID >= id {
+				id = n.ID + 1
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(in))
+	for key := range in {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, n := range in[key] {
+			found := false
+			for i, mine := range b.Notes[key] {
+				if !mine.Created.Equal(n.Created) {
+					continue
+				}
+
+				found = true
+				if newest && n.Updated.After(mine.Updated) {
+					b.Notes[key][i].Text, b.Notes[key][i].Updated = n.Text, n.Updated
+				}
+				break
+			}
+
+			if !found {
+				n.ID = id
+				id++
+				b.Notes[key] = append(b.Notes[key], n)
+			}
+		}
+	}
+}
+
+// changed returns when something was last changed, or when it was made if
+// that isn't known
+func changed(updated, created time.Time) time.Time {
+	if updated.IsZero() {
+		return created
+	}
+
+	return updated
+}
+
+// reviewed returns when a card was last reviewed, or added if it hasn't been
+func reviewed(c *memorize.Card) time.Time {
+	return changed(c.Reviewed, c.Added)
+}
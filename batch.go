@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/render"
+)
+
+// batchEntry is one line of a batch of references given to read. A line may
+// hold several references separated by semicolons.
+type batchEntry struct {
+	Input    string             `json:"input"`
+	Passages []*passage.Passage `json:"passages,omitempty"`
+	Error    string             `json:"error,omitempty"`
+
+	// refs are the references on the line, and groups holds their
+	// passages, one per translation
+	refs   []string
+	groups [][]*passage.Passage
+	err    error
+	done   chan struct{}
+}
+
+// readBatch reads one entry per line, skipping blank lines and comments
+// starting with #
+func readBatch(r io.Reader) ([]*batchEntry, error) {
+	var entries []*batchEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entries = append(entries, &batchEntry{Input: line, done: make(chan struct{})})
+	}
+
+	return entries, scanner.Err()
+}
+
+// fetch fetches the passages for each reference on the line
+func (e *batchEntry) fetch(fetch func(refString string) ([]*passage.Passage, error)) {
+	for _, refString := range strings.Split(e.Input, ";") {
+		if refString = strings.TrimSpace(refString); refString == "" {
+			continue
+		}
+
+		passages, err := fetch(refString)
+		if err != nil {
+			e.err = err
+			return
+		}
+
+		e.refs = append(e.refs, refString)
+		e.groups = append(e.groups, passages)
+		e.Passages = append(e.Passages, passages...)
+	}
+}
+
+// fetchBatch starts fetching the entries in the background, at most jobs at a
+// time. Each entry's done channel is closed once it has been fetched.
+func fetchBatch(entries []*batchEntry, jobs int, fetch func(refString string) ([]*passage.Passage, error)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	sem := make(chan struct{}, jobs)
+	go func() {
+		for _, e := range entries {
+			sem <- struct{}{}
+			go func(e *batchEntry) {
+				defer func() {
+					close(e.done)
+					<-sem
+				}()
+				e.fetch(fetch)
+			}(e)
+		}
+	}()
+}
+
+// writeBatch writes the entries in the order they were given, as each one
+// arrives. JSON is written as one object per entry per line; other formats
+// are written by write, with a separator between entries. Entries that
+// couldn't be fetched are reported on stderr, and writeBatch returns false if
+// there were any.
+func writeBatch(w io.Writer, entries []*batchEntry, format string, write func(w io.Writer, passages []*passage.Passage) error) (bool, error) {
+	ok, written := true, false
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		<-e.done
+		if e.err != nil {
+			ok = false
+			e.Error = e.err.Error()
+			fmt.Fprintf(os.Stderr, "%s: %s\n", e.Input, e.err)
+		}
+
+		if format == "json" {
+			if err := enc.Encode(e); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if e.err != nil {
+			continue
+		}
+
+		if written {
+			if _, err := io.WriteString(w, render.Separator(format)); err != nil {
+				return false, err
+			}
+		}
+		written = true
+
+		for i, passages := range e.groups {
+			if i > 0 {
+				if _, err := io.WriteString(w, "\n"); err != nil {
+					return false, err
+				}
+			}
+
+			if err := write(w, passages); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return ok, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtjm/bible/passage"
+)
+
+// fakeFetch returns a passage named after the reference, taking longer for
+// those earlier in the batch so that they finish out of order, and fails for
+// "Nowhere"
+func fakeFetch(refString string) ([]*passage.Passage, error) {
+	if strings.HasPrefix(refString, "Nowhere") {
+		return nil, fmt.Errorf("no such book")
+	}
+
+	delay := map[string]time.Duration{"John 1": 30, "John 2": 20, "John 3": 10}[refString]
+	time.Sleep(delay * time.Millisecond)
+	return []*passage.Passage{{Reference: refString, Translation: "ESV"}}, nil
+}
+
+func writeRefs(w io.Writer, passages []*passage.Passage) error {
+	_, err := fmt.Fprintln(w, passages[0].Reference)
+	return err
+}
+
+func TestReadBatch(t *testing.T) {
+	entries, err := readBatch(strings.NewReader("# comments and blank lines are skipped\n\nJohn 1\n  Rom 5:8; 1 John 4:9-10;  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inputs []string
+	for _, e := range entries {
+		inputs = append(inputs, e.Input)
+	}
+	if got := strings.Join(inputs, " | "); got != "John 1 | Rom 5:8; 1 John 4:9-10;" {
+		t.Fatalf("readBatch -> %s", got)
+	}
+
+	entries[1].fetch(fakeFetch)
+	if got := strings.Join(entries[1].refs, " | "); got != "Rom 5:8 | 1 John 4:9-10" || len(entries[1].groups) != 2 {
+		t.Errorf("fetch split the line into %s, with %d groups", got, len(entries[1].groups))
+	}
+}
+
+// sep is what goes between entries in text
+var sep = "\n" + strings.Repeat("-", 40) + "\n\n"
+
+func TestWriteBatch(t *testing.T) {
+	cases := []struct {
+		jobs int
+		want string
+	}{
+		{1, "John 1\n" + sep + "John 2\n" + sep + "John 3\n\nJohn 4\n"},
+		{3, "John 1\n" + sep + "John 2\n" + sep + "John 3\n\nJohn 4\n"},
+	}
+
+	for _, c := range cases {
+		entries, _ := readBatch(strings.NewReader("John 1\nNowhere 1\nJohn 2\nJohn 3; John 4\n"))
+		fetchBatch(entries, c.jobs, fakeFetch)
+
+		var buf bytes.Buffer
+		ok, err := writeBatch(&buf, entries, "text", writeRefs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Errorf("jobs %d: writeBatch -> ok despite an entry that couldn't be fetched", c.jobs)
+		}
+		if got := buf.String(); got != c.want {
+			t.Errorf("jobs %d: writeBatch -> %q, wanted %q", c.jobs, got, c.want)
+		}
+	}
+}
+
+func TestWriteBatchJSON(t *testing.T) {
+	entries, _ := readBatch(strings.NewReader("John 3\nNowhere 1\n"))
+	fetchBatch(entries, 2, fakeFetch)
+
+	var buf bytes.Buffer
+	ok, err := writeBatch(&buf, entries, "json", writeRefs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("writeBatch -> ok despite an entry that couldn't be fetched")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("writeBatch wrote %d lines, wanted 2:\n%s", len(lines), buf.String())
+	}
+
+	var got []map[string]interface{}
+	for _, line := range lines {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("line %q: %s", line, err)
+		}
+		got = append(got, m)
+	}
+	if got[0]["input"] != "John 3" || got[0]["error"] != nil || len(got[0]["passages"].([]interface{})) != 1 {
+		t.Errorf("first line -> %v", got[0])
+	}
+	if got[1]["input"] != "Nowhere 1" || got[1]["error"] != "no such book" || got[1]["passages"] != nil {
+		t.Errorf("second line -> %v", got[1])
+	}
+}
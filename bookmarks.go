@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codegangsta/cli"
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/settings"
+)
+
+// bashCompletion is sourced from .bashrc to complete bible's commands,
+// bookmarks and books. zsh can use it too after bashcompinit.
+const bashCompletion = `_bible_complete() {
+	local cur opts
+	COMPREPLY=()
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion )
+	COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
+	return 0
+}
+
+complete -F _bible_complete bible
+`
+
+// printBookmarks lists the named bookmarks, a line each
+func (c *config) printBookmarks(w io.Writer, names []string) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	for _, name := range names {
+		b := c.Bookmarks[name]
+		tags := make([]string, len(b.Tags))
+		for i, t := range b.Tags {
+			tags[i] = "#" + t
+		}
+		fmt.Fprintf(tw, "%s:\t%s\t%s\t%s\n", name, c.formatRef(b.Ref), strings.Join(tags, " "), b.Description)
+	}
+	tw.Flush()
+
+	return writeColumns(w, buf.String())
+}
+
+// writeColumns writes lines laid out in columns by a tabwriter, without the
+// spaces it leaves after rows whose last columns are empty
+func writeColumns(w io.Writer, columns string) error {
+	for _, line := range strings.SplitAfter(columns, "\n") {
+		if line != "" {
+			if _, err := fmt.Fprintln(w, strings.TrimRight(line, " \n")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// printBookmark shows everything about a bookmark
+func (c *config) printBookmark(w io.Writer, name string, b settings.Bookmark) {
+	fmt.Fprintf(w, "%s:\t%s\n", name, c.formatRef(b.Ref))
+	if b.Description != "" {
+		fmt.Fprintf(w, "note:\t%s\n", b.Description)
+	}
+	if len(b.Tags) > 0 {
+		fmt.Fprintf(w, "tags:\t%s\n", strings.Join(b.Tags, ", "))
+	}
+	if b.Step != "" {
+		fmt.Fprintf(w, "step:\t%s\n", b.Step)
+	}
+	if b.Loop != "" {
+		fmt.Fprintf(w, "loop:\t%s\n", c.formatRef(b.Loop))
+	}
+	if !b.Created.IsZero() {
+		fmt.Fprintf(w, "created:\t%s\n", b.Created.Local().Format("2006-01-02 15:04"))
+	}
+	if !b.Updated.IsZero() {
+		fmt.Fprintf(w, "updated:\t%s\n", b.Updated.Local().Format("2006-01-02 15:04"))
+	}
+}
+
+// editTags adds the comma-separated tags in add to a bookmark and takes away
+// those in remove
+func editTags(b *settings.Bookmark, add, remove string) {
+	for _, t := range strings.Split(add, ",") {
+		if t = strings.TrimSpace(t); t != "" && !b.HasTag(t) {
+			b.Tags = append(b.Tags, t)
+		}
+	}
+
+	var kept []string
+	for _, t := range b.Tags {
+		gone := false
+		for _, r := range strings.Split(remove, ",") {
+			gone = gone || strings.EqualFold(t, strings.TrimSpace(r))
+		}
+		if !gone {
+			kept = append(kept, t)
+		}
+	}
+	b.Tags = kept
+}
+
+// completeRef completes the first word of a reference: one of words, or a
+// book
+func completeRef(ctx *cli.Context, words ...string) {
+	if len(ctx.Args()) > 0 {
+		return
+	}
+
+	for _, w := range words {
+		fmt.Println(w)
+	}
+	completeBooks()
+}
+
+// completeBooks prints the names of the books for tab completion, without
+// spaces so that the shell takes each as one word
+func completeBooks() {
+	for b := ref.Genesis; b <= ref.Revelation; b++ {
+		fmt.Println(strings.Replace(b.String(), " ", "", -1))
+	}
+}
+
+// completeBookmarks completes the name of a bookmark, then a reference
+func (c *config) completeBookmarks(ctx *cli.Context) {
+	if len(ctx.Args()) > 0 && !ctx.Bool("delete") && !ctx.Bool("rename") {
+		completeBooks()
+		return
+	}
+
+	names, _ := c.BookmarkNames("name", "")
+	for _, name := range names {
+		fmt.Println(name)
+	}
+}
@@ -0,0 +1,219 @@
+// Package cursor moves reading positions, such as the "next" bookmark,
+// through the Bible a step at a time.
+package cursor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+// Units a step can be counted in
+const (
+	Chapters = "chapters"
+	Verses   = "verses"
+
+	// Pericope reads on to the end of the section the cursor is in, which
+	// can only be found in the text
+	Pericope = "pericope"
+)
+
+// Step is how far a cursor moves each time it is read
+type Step struct {
+	N    int
+	Unit string
+}
+
+// DefaultStep is a chapter at a time
+var DefaultStep = Step{N: 1, Unit: Chapters}
+
+var stepRegex = regexp.MustCompile(`^(\d*)\s*([a-z]+)$`)
+
+// ParseStep parses a step such as "2 chapters", "3ch", "10 verses", "5v" or
+// "pericope". Units may be shortened to any prefix. An empty step is
+// DefaultStep.
+func ParseStep(s string) (Step, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return DefaultStep, nil
+	}
+
+	m := stepRegex.FindStringSubmatch(s)
+	if m == nil {
+		return Step{}, fmt.Errorf("Error parsing step %q: expected e.g. \"2 chapters\", \"10 verses\" or \"pericope\"", s)
+	}
+
+	n := 1
+	if m[1] != "" {
+		n, _ = strconv.Atoi(m[1])
+	}
+	if n < 1 {
+		return Step{}, fmt.Errorf("Error parsing step %q: it must move at least 1", s)
+	}
+
+	for _, unit := range []string{Chapters, Verses, Pericope} {
+		if strings.HasPrefix(unit, m[2]) || m[2] == unit+"s" {
+			if unit == Pericope && n != 1 {
+				return Step{}, fmt.Errorf("Error parsing step %q: a pericope step reads to the end of one pericope", s)
+			}
+			return Step{N: n, Unit: unit}, nil
+		}
+	}
+
+	return Step{}, fmt.Errorf("Error parsing step %q: unknown unit %q, expected chapters, verses or pericope", s, m[2])
+}
+
+func (s Step) String() string {
+	if s.Unit == Pericope {
+		return Pericope
+	}
+	if s.N == 1 {
+		return "1 " + strings.TrimSuffix(s.Unit, "s")
+	}
+
+	return fmt.Sprintf("%d %s", s.N, s.Unit)
+}
+
+// Passage returns what a cursor at at reads in a step counted in chapters or
+// verses. It stops at the end of loop, if given, or of the Bible.
+func (s Step) Passage(at *ref.Ref, loop *ref.Range) *ref.Range {
+	if s.Unit == Verses {
+		start := at.Add(0)
+		end := start.Add(s.N - 1)
+		if loop != nil && loop.Verses().End.Less(end) {
+			end = &loop.Verses().End
+		}
+		return &ref.Range{Start: *start, End: *end}
+	}
+
+	end := chapterOf(at)
+	for i := 1; i < s.N; i++ {
+		next := end.NextChapter()
+		if next.Book() < end.Book() || loop != nil && !loop.Contains(next.Add(0)) {
+			break
+		}
+		end = next
+	}
+
+	start := *at
+	if start.Chapter() == 0 {
+		start = *chapterOf(at)
+	}
+	if start.Verse() > 0 {
+		// Read from the verse to the end of the chapter
+		end = ref.New(end.Book(), end.Chapter(), end.Book().Verses(end.Chapter()))
+	}
+
+	return &ref.Range{Start: start, End: *end}
+}
+
+// Section returns what a cursor at at reads in a Pericope step: on to the
+// verse before the next section heading in p, which should be the chapter at
+// is in, fetched with headings. Without another heading it reads to the end
+// of the chapter. It stops at the end of loop, if given.
+func Section(p *passage.Passage, at *ref.Ref, loop *ref.Range) *ref.Range {
+	start := at.Add(0)
+	end := ref.New(start.Book(), start.Chapter(), start.Book().Verses(start.Chapter()))
+	for i, v := range p.Verses {
+		if i > 0 && len(v.Headings) > 0 && start.Less(&v.Ref) {
+			end = &p.Verses[i-1].Ref
+			break
+		}
+	}
+	if loop != nil && loop.Verses().End.Less(end) {
+		end = &loop.Verses().End
+	}
+
+	return &ref.Range{Start: *start, End: *end}
+}
+
+// Budget returns what a cursor at at reads in about words words. verses is
+// the text from the start of the cursor's chapter on, fetched with headings,
+// and should run on well past the budget unless it reaches the end of loop
+// or of the Bible. The reading ends at whichever break between paragraphs,
+// sections or chapters comes closest to the budget, but takes in at least one
+// paragraph. It stops at the end of loop, if given.
+func Budget(verses []passage.Verse, at *ref.Ref, words int, loop *ref.Range) *ref.Range {
+	start := at.Add(0)
+	var text []passage.Verse
+	for _, v := range verses {
+		if !v.Ref.Less(start) && (loop == nil || !loop.Verses().End.Less(&v.Ref)) {
+			text = append(text, v)
+		}
+	}
+	if len(text) == 0 {
+		return &ref.Range{Start: *start, End: *start}
+	}
+
+	// The break before text[i] comes after count words
+	end, best := -1, 0
+	count, over := 0, false
+	for i, v := range text {
+		if i > 0 && (v.Paragraph || len(v.Headings) > 0 || v.Ref.Verse() == 1) {
+			if off := abs(count - words); end < 0 || off < best {
+				end, best = i-1, off
+			}
+			if count >= words {
+				over = true
+				break
+			}
+		}
+		count += len(strings.Fields(v.Text))
+	}
+
+	// The end of the text is a break too
+	if !over {
+		if off := abs(count - words); end < 0 || off < best {
+			end = len(text) - 1
+		}
+	}
+
+	return &ref.Range{Start: *start, End: text[end].Ref}
+}
+
+// After returns where a cursor moves to once it has read r: the next chapter
+// if r reads to the end of one, or else the next verse. At the end of loop,
+// if given, it goes back to the start of the loop, and at the end of the
+// Bible, back to Genesis.
+func After(r *ref.Range, loop *ref.Range) *ref.Ref {
+	end := r.End
+	var next *ref.Ref
+	switch {
+	case end.Chapter() == 0:
+		next = ref.New(end.Book().Next(), 1, 0)
+	case end.Verse() == 0 || end.Verse() == end.Book().Verses(end.Chapter()):
+		next = end.NextChapter()
+	default:
+		next = end.Add(1)
+	}
+
+	if loop != nil && !loop.Contains(next.Add(0)) {
+		next = chapterOf(&loop.Start)
+		if loop.Start.Verse() > 0 {
+			next = &loop.Start
+		}
+	}
+
+	return next
+}
+
+// chapterOf returns the whole chapter a reference is in, taking a whole book
+// to be its first chapter
+func chapterOf(r *ref.Ref) *ref.Ref {
+	if r.Chapter() == 0 {
+		return ref.New(r.Book(), 1, 0)
+	}
+
+	return ref.New(r.Book(), r.Chapter(), 0)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,178 @@
+package cursor
+
+import (
+	"testing"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+func TestParseStep(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Step
+	}{
+		{"", Step{1, Chapters}},
+		{"2 chapters", Step{2, Chapters}},
+		{"1 chapter", Step{1, Chapters}},
+		{"3ch", Step{3, Chapters}},
+		{"10 verses", Step{10, Verses}},
+		{"5v", Step{5, Verses}},
+		{"pericope", Step{1, Pericope}},
+		{"Pericopes", Step{1, Pericope}},
+	}
+
+	for _, c := range cases {
+		got, err := ParseStep(c.in)
+		if err != nil {
+			t.Errorf("ParseStep(%q): %s", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("ParseStep(%q) -> %v, wanted %v", c.in, got, c.want)
+		}
+	}
+
+	for _, in := range []string{"0 chapters", "2 pericopes", "3 weeks", "chapters 3"} {
+		if _, err := ParseStep(in); err == nil {
+			t.Errorf("expected an error for %q", in)
+		}
+	}
+
+	if s := (Step{3, Verses}).String(); s != "3 verses" {
+		t.Errorf("String() -> %v", s)
+	}
+	if s := (Step{1, Chapters}).String(); s != "1 chapter" {
+		t.Errorf("String() -> %v", s)
+	}
+}
+
+func TestPassage(t *testing.T) {
+	cases := []struct {
+		at, step, loop, read, after string
+	}{
+		{"Genesis 1", "1 chapter", "", "Genesis 1", "Genesis 2"},
+		{"Psalm 149", "3 chapters", "Psalms", "Psalm 149-150", "Psalm 1"},
+		{"Proverbs 31", "1 chapter", "Proverbs 1-31", "Proverbs 31", "Proverbs 1"},
+		{"Malachi 4", "2 chapters", "", "Malachi 4-Matthew 1", "Matthew 2"},
+		{"Revelation 22", "2 chapters", "", "Revelation 22", "Genesis 1"},
+		{"John 3", "10 verses", "", "John 3:1-10", "John 3:11"},
+		{"John 3:31", "10 verses", "", "John 3:31-4:4", "John 4:5"},
+		{"John 3:27", "10 verses", "", "John 3:27-36", "John 4"},
+		{"Jude 1:20", "10 verses", "", "Jude 1:20-25", "Revelation 1"},
+		{"Romans 8:30", "10 verses", "Romans 8", "Romans 8:30-39", "Romans 8"},
+		{"Romans 3:21", "2 chapters", "", "Romans 3:21-4:25", "Romans 5"},
+		{"Ruth", "1 chapter", "", "Ruth 1", "Ruth 2"},
+	}
+
+	for _, c := range cases {
+		at, err := ref.Parse(c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, err := ParseStep(c.step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loop *ref.Range
+		if c.loop != "" {
+			if loop, err = ref.ParseRange(c.loop); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		read := step.Passage(at, loop)
+		if read.String() != c.read {
+			t.Errorf("(%v).Passage(%v, %v) -> %v, wanted %v", step, c.at, c.loop, read, c.read)
+		}
+		if after := After(read, loop); after.String() != c.after {
+			t.Errorf("After(%v, %v) -> %v, wanted %v", read, c.loop, after, c.after)
+		}
+	}
+}
+
+func TestSection(t *testing.T) {
+	// Mark 4 with sections starting at verses 1, 10, 21 and 35
+	p := &passage.Passage{}
+	for v := 1; v <= ref.Mark.Verses(4); v++ {
+		verse := passage.Verse{Ref: *ref.New(ref.Mark, 4, v)}
+		if v == 1 || v == 10 || v == 21 || v == 35 {
+			verse.Headings = []passage.Heading{{Level: 1, Text: "Heading"}}
+		}
+		p.Verses = append(p.Verses, verse)
+	}
+
+	cases := []struct {
+		at, loop, read string
+	}{
+		{"Mark 4", "", "Mark 4:1-9"},
+		{"Mark 4:10", "", "Mark 4:10-20"},
+		{"Mark 4:12", "", "Mark 4:12-20"},
+		{"Mark 4:35", "", "Mark 4:35-41"},
+		{"Mark 4:1", "Mark 4:1-5", "Mark 4:1-5"},
+	}
+
+	for _, c := range cases {
+		at, err := ref.Parse(c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loop *ref.Range
+		if c.loop != "" {
+			if loop, err = ref.ParseRange(c.loop); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if read := Section(p, at, loop); read.String() != c.read {
+			t.Errorf("Section(%v, %v) -> %v, wanted %v", c.at, c.loop, read, c.read)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	// Mark 4 and the start of Mark 5, ten words a verse, with paragraphs
+	// starting at 4:1, 4:10, 4:21, 4:35 and 5:1
+	var verses []passage.Verse
+	for _, r := range []*ref.Ref{ref.New(ref.Mark, 4, 1), ref.New(ref.Mark, 5, 1)} {
+		last := ref.Mark.Verses(r.Chapter())
+		if r.Chapter() == 5 {
+			last = 5
+		}
+		for v := 1; v <= last; v++ {
+			verse := passage.Verse{Ref: *ref.New(ref.Mark, r.Chapter(), v), Text: "one two three four five six seven eight nine ten"}
+			verse.Paragraph = v == 1 || r.Chapter() == 4 && (v == 10 || v == 21 || v == 35)
+			verses = append(verses, verse)
+		}
+	}
+
+	cases := []struct {
+		at    string
+		words int
+		loop  string
+		read  string
+	}{
+		{"Mark 4", 100, "", "Mark 4:1-9"},
+		{"Mark 4", 160, "", "Mark 4:1-20"},
+		{"Mark 4:12", 10, "", "Mark 4:12-20"},
+		{"Mark 4", 1000, "Mark 4", "Mark 4:1-41"},
+		{"Mark 4:35", 1000, "", "Mark 4:35-5:5"},
+	}
+
+	for _, c := range cases {
+		at, err := ref.Parse(c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loop *ref.Range
+		if c.loop != "" {
+			if loop, err = ref.ParseRange(c.loop); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if read := Budget(verses, at, c.words, loop); read.String() != c.read {
+			t.Errorf("Budget(%v, %d, %v) -> %v, wanted %v", c.at, c.words, c.loop, read, c.read)
+		}
+	}
+}
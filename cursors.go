@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/dtjm/bible/cursor"
+	"github.com/dtjm/bible/history"
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+// defaultSpeeds are the words per minute read and play are taken to go at
+// until the history has sessions to measure them by. The ESV audio is read at
+// about 150.
+var defaultSpeeds = map[string]float64{
+	"read": 200,
+	"play": 150,
+}
+
+// reading is a passage read from a cursor or a plan, along with what to
+// record once it has been read
+type reading struct {
+	read string
+
+	// For a cursor, where it was and where it moves on to afterwards
+	cursor string
+	at     string
+	after  string
+
+	// For a plan, the day it is read for. The day is done once its last
+	// reading has been read.
+	plan      string
+	day       int
+	dayName   string
+	lastOfDay bool
+}
+
+// question returns what to ask, when confirming, before the reading is
+// recorded, or "" if there is nothing to ask yet
+func (r *reading) question() string {
+	switch {
+	case r.cursor != "":
+		return r.read
+	case r.lastOfDay:
+		return r.plan + " " + r.dayName
+	}
+
+	return ""
+}
+
+// readings resolves what read and play were asked for into the passages to
+// read: a cursor given with --advance, "next", "today" for every cursor, or a
+// plan: reference. It returns nil for an ordinary reference. With --minutes,
+// the cursors read for about that long between them rather than by their
+// steps.
+func (c *config) readings(ctx *cli.Context, refString, translation string) ([]*reading, error) {
+	var names []string
+	lower := strings.ToLower(strings.TrimSpace(refString))
+	minutes := ctx.Float64("minutes")
+	switch {
+	case ctx.String("advance") != "":
+		names = []string{ctx.String("advance")}
+	case lower == "next":
+		names = []string{"next"}
+	case lower == "today":
+		names = c.Cursors()
+	case minutes > 0:
+		return nil, fmt.Errorf("--minutes only works with next, today or --advance")
+	case strings.HasPrefix(lower, "plan:"):
+		return c.planReadings(strings.TrimPrefix(lower, "plan:"), time.Now())
+	default:
+		return nil, nil
+	}
+
+	words := 0
+	if minutes > 0 && len(names) > 0 {
+		words = budget(ctx.Command.Name, minutes) / len(names)
+	}
+
+	var readings []*reading
+	for _, name := range names {
+		r, err := c.cursorReading(name, translation, words)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("%s:\t%s", name, r.read)
+		readings = append(readings, r)
+	}
+
+	return readings, nil
+}
+
+// budget returns how many words can be read with command in minutes, at the
+// speed measured from the history
+func budget(command string, minutes float64) int {
+	entries, err := history.Load(historyFile())
+	if err != nil {
+		log.Printf("Error reading history: %s", err)
+	}
+
+	speed, ok := history.Speed(entries, command)
+	if !ok {
+		speed = defaultSpeeds[command]
+	}
+	log.Printf("%s at %.0f words per minute", command, speed)
+
+	return int(speed*minutes + 0.5)
+}
+
+// cursorReading works out what the cursor called name reads next. A pericope
+// step fetches the chapter in translation to find where the section ends. If
+// words is more than 0, the cursor reads about that many words instead of its
+// step, fetching chapters in translation to count them.
+func (c *config) cursorReading(name, translation string, words int) (*reading, error) {
+	b, ok := c.Bookmarks[name]
+	if !ok {
+		return nil, fmt.Errorf("You don't have a bookmark called %q", name)
+	}
+
+	at, err := ref.Parse(b.Ref)
+	if err != nil {
+		return nil, err
+	}
+	step, err := cursor.ParseStep(b.Step)
+	if err != nil {
+		return nil, fmt.Errorf("The %q bookmark has a bad step: %s", name, err)
+	}
+	var loop *ref.Range
+	if b.Loop != "" {
+		if loop, err = ref.ParseRange(b.Loop); err != nil {
+			return nil, fmt.Errorf("The %q bookmark has a bad loop: %s", name, err)
+		}
+	}
+
+	var r *ref.Range
+	if words > 0 {
+		verses, err := c.fetchWords(translation, at, words, loop)
+		if err != nil {
+			return nil, err
+		}
+		r = cursor.Budget(verses, at, words, loop)
+	} else if step.Unit == cursor.Pericope {
+		opts := c.Read
+		opts.Headings = true
+		chapter := ref.Range{Start: *at, End: *at}
+		p, err := c.fetchPassage(translation, chapter.Chapters().String(), opts)
+		if err != nil {
+			return nil, err
+		}
+		r = cursor.Section(p, at, loop)
+	} else {
+		r = step.Passage(at, loop)
+	}
+
+	return &reading{
+		cursor: name,
+		at:     b.Ref,
+		read:   r.String(),
+		after:  cursor.After(r, loop).String(),
+	}, nil
+}
+
+// fetchWords fetches the chapters from the one at is in, with headings, until
+// they hold at least words words from at on, or reach the end of loop or of
+// the Bible
+func (c *config) fetchWords(translation string, at *ref.Ref, words int, loop *ref.Range) ([]passage.Verse, error) {
+	opts := c.Read
+	opts.Headings = true
+
+	start := at.Add(0)
+	var verses []passage.Verse
+	count := 0
+	for chapter := ref.New(start.Book(), start.Chapter(), 0); ; chapter = chapter.NextChapter() {
+		if len(verses) > 0 && loop != nil && !loop.Contains(chapter.Add(0)) {
+			break
+		}
+
+		p, err := c.fetchPassage(translation, chapter.String(), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range p.Verses {
+			if !v.Ref.Less(start) {
+				count += len(strings.Fields(v.Text))
+			}
+		}
+		verses = append(verses, p.Verses...)
+
+		if count >= words || chapter.Book() == ref.Revelation && chapter.Chapter() == ref.Revelation.Chapters() {
+			break
+		}
+	}
+
+	return verses, nil
+}
+
+// checkCursor checks a cursor's step and loop before they are saved, and
+// returns the step written out in full
+func checkCursor(step, loop string) (string, error) {
+	if loop != "" {
+		if _, err := ref.ParseRange(loop); err != nil {
+			return "", err
+		}
+	}
+	if step == "" {
+		return "", nil
+	}
+
+	s, err := cursor.ParseStep(step)
+	if err != nil {
+		return "", err
+	}
+
+	return s.String(), nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/settings"
+	"github.com/dtjm/bible/votd"
+)
+
+// votdCache is the verse of the day as last fetched
+type votdCache struct {
+	Date        string           `json:"date"`
+	Translation string           `json:"translation"`
+	Canon       bool             `json:"canon"`
+	Passage     *passage.Passage `json:"passage"`
+}
+
+// verseOfTheDay returns the verse of the day for date, fetching it unless it
+// is already in the cache
+func (c *config) verseOfTheDay(date time.Time, translation string, canon bool) (*passage.Passage, error) {
+	file := filepath.Join(settings.CacheDir(), "votd.json")
+	key := votdCache{Date: date.Format(votd.DateFormat), Translation: translation, Canon: canon}
+
+	var cached votdCache
+	if data, err := ioutil.ReadFile(file); err == nil && json.Unmarshal(data, &cached) == nil {
+		if cached.Passage != nil && cached.Date == key.Date &&
+			cached.Translation == key.Translation && cached.Canon == key.Canon {
+			return cached.Passage, nil
+		}
+	}
+
+	p, err := c.fetchPassage(translation, votd.Pick(date, canon).String(), c.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	// Failing to cache it only means fetching it again
+	key.Passage = p
+	if data, err := json.Marshal(key); err == nil {
+		settings.WriteData(file, data)
+	}
+
+	return p, nil
+}
+
+// oneLine returns a passage as a single line, such as for a shell prompt. If
+// it is longer than width, the text is cut short with an ellipsis. The
+// reference is written in refStyle.
+func oneLine(p *passage.Passage, width int, refStyle string) string {
+	texts := make([]string, len(p.Verses))
+	for i, v := range p.Verses {
+		texts[i] = v.Text
+	}
+
+	text := []rune(strings.Join(strings.Fields(strings.Join(texts, " ")), " "))
+	suffix := " — " + p.Range.Format(refStyle) + " (" + p.Translation + ")"
+
+	if max := width - len([]rune(suffix)); width > 0 && len(text) > max {
+		if max < 1 {
+			max = 1
+		}
+		text = append([]rune(strings.TrimSpace(string(text[:max-1]))), '…')
+	}
+
+	return string(text) + suffix
+}
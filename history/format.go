@@ -0,0 +1,58 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Formats lists the formats Write can produce
+var Formats = []string{"text", "json", "csv"}
+
+// TimeFormat is how times are written in the text format
+const TimeFormat = "2006-01-02 15:04"
+
+// Write writes entries in one of Formats, with references in refStyle. JSON
+// is written a line per entry, as in the log itself.
+func Write(w io.Writer, entries []Entry, format, refStyle string) error {
+	switch strings.ToLower(format) {
+	case "", "text":
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format(TimeFormat),
+				e.Command, e.Range.Format(refStyle), e.Translation, e.Duration/time.Second*time.Second)
+		}
+		return tw.Flush()
+
+	case "json":
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"time", "command", "ref", "translation", "seconds"})
+		for _, e := range entries {
+			cw.Write([]string{
+				e.Time.Format(time.RFC3339),
+				e.Command,
+				e.Range.Format(refStyle),
+				e.Translation,
+				strconv.FormatFloat(e.Duration.Seconds(), 'f', 1, 64),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return fmt.Errorf("Unknown format %q, expected one of %q", format, Formats)
+}
@@ -0,0 +1,288 @@
+// Package history keeps a log of the passages that have been read. The log
+// is a file of JSON lines that is only appended to, except when a backup is
+// imported into it.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/settings"
+)
+
+// Entry is a passage that was read
+type Entry struct {
+	Time        time.Time
+	Command     string
+	Range       ref.Range
+	Translation string
+
+	// Duration is how long the command took, from fetching the passage to
+	// the end of paging through it or playing it
+	Duration time.Duration
+
+	// Words is the number of words in the passage, or 0 if it wasn't
+	// counted
+	Words int
+}
+
+type jsonEntry struct {
+	Time        time.Time `json:"time"`
+	Command     string    `json:"command"`
+	Ref         string    `json:"ref"`
+	Translation string    `json:"translation"`
+	Seconds     float64   `json:"seconds"`
+	Words       int       `json:"words,omitempty"`
+}
+
+// MarshalJSON encodes the entry with the reference written out, e.g.
+//
+//	{"time": "2026-10-19T07:30:00Z", "command": "read", "ref": "John 3",
+//	 "translation": "ESV", "seconds": 95.2, "words": 871}
+func (e Entry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonEntry{
+		Time:        e.Time,
+		Command:     e.Command,
+		Ref:         e.Range.String(),
+		Translation: e.Translation,
+		Seconds:     e.Duration.Seconds(),
+		Words:       e.Words,
+	})
+}
+
+// UnmarshalJSON decodes an entry written by MarshalJSON
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	var j jsonEntry
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	r, err := ref.ParseRange(j.Ref)
+	if err != nil {
+		return err
+	}
+
+	*e = Entry{
+		Time:        j.Time,
+		Command:     j.Command,
+		Range:       *r,
+		Translation: j.Translation,
+		Duration:    time.Duration(j.Seconds * float64(time.Second)),
+		Words:       j.Words,
+	}
+	return nil
+}
+
+// Append adds an entry to the end of the log at path
+func Append(path string, e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	// Taking the lock keeps the entry from being lost while an import
+	// replaces the log
+	lock, err := settings.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	// A single write of a whole line, so that entries from processes
+	// running at once can't be interleaved
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Save replaces the log at path with entries, in one step. The caller
+// should hold the lock on path, as taken by settings.LockFile, so that no
+// entry is appended in the meantime and lost.
+func Save(path string, entries []Entry) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, entries, "json", ""); err != nil {
+		return err
+	}
+
+	return settings.WriteData(path, buf.Bytes())
+}
+
+// Load reads the log at path. A missing log is empty.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Read reads a log of JSON lines, skipping blank lines
+func Read(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("Error parsing history line %d: %s", n, err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, scanner.Err()
+}
+
+// Filter picks out entries of the log
+type Filter struct {
+	// Since leaves out entries before it, unless it is zero
+	Since time.Time
+
+	// Books leaves out entries that don't touch one of them, unless empty
+	Books []ref.Book
+
+	// Command leaves out entries from other commands, unless empty
+	Command string
+}
+
+// Match reports whether an entry passes the filter
+func (f Filter) Match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+
+	if f.Command != "" && !strings.EqualFold(f.Command, e.Command) {
+		return false
+	}
+
+	if len(f.Books) == 0 {
+		return true
+	}
+	for _, b := range f.Books {
+		if e.Range.Start.Book() <= b && b <= e.Range.End.Book() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply returns the entries that pass the filter
+func (f Filter) Apply(entries []Entry) []Entry {
+	var matched []Entry
+	for _, e := range entries {
+		if f.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+
+	return matched
+}
+
+// ParseSince parses the start of a period of history, either a date such as
+// "2026-10-01" or a time ago such as "36h", "7d" or "2w", counting back
+// from now
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+
+	days := map[string]int{"d": 1, "w": 7}
+	if len(s) > 1 {
+		if unit, ok := days[s[len(s)-1:]]; ok {
+			var n int
+			if _, err := fmt.Sscanf(s[:len(s)-1], "%d", &n); err == nil && n >= 0 {
+				return now.AddDate(0, 0, -n*unit), nil
+			}
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("Error parsing %q: expected a date such as 2026-10-01 or a time ago such as 7d", s)
+}
+
+// ReadChapters returns the chapters that the entries cover from start to
+// end. Reading part of a chapter doesn't count.
+func ReadChapters(entries []Entry) map[ref.Ref]bool {
+	read := make(map[ref.Ref]bool)
+	for _, e := range entries {
+		v := e.Range.Verses()
+		last := ref.New(v.End.Book(), v.End.Chapter(), 0)
+		for c := ref.New(v.Start.Book(), v.Start.Chapter(), 0); ; c = c.NextChapter() {
+			whole := (&ref.Range{Start: *c, End: *c}).Verses()
+			if e.Range.Contains(&whole.Start) && e.Range.Contains(&whole.End) {
+				read[*c] = true
+			}
+			if *c == *last {
+				break
+			}
+		}
+	}
+
+	return read
+}
+
+// Speeds of reading and listening, in words per minute, outside which an
+// entry is taken not to have been read through at the time it took: piped
+// to a file, say, or left open on the screen
+const (
+	minSpeed = 50
+	maxSpeed = 1000
+)
+
+// speedSessions is how many of the most recent sessions the speed is
+// measured over
+const speedSessions = 50
+
+// Speed returns the words per minute the passages read with command were
+// read at, going by the most recent entries with a word count, and false if
+// there are none to go by
+func Speed(entries []Entry, command string) (float64, bool) {
+	var words, minutes float64
+	sessions := 0
+	for i := len(entries) - 1; i >= 0 && sessions < speedSessions; i-- {
+		e := entries[i]
+		if e.Words == 0 || e.Duration <= 0 || !strings.EqualFold(e.Command, command) {
+			continue
+		}
+
+		m := e.Duration.Minutes()
+		if speed := float64(e.Words) / m; speed < minSpeed || speed > maxSpeed {
+			continue
+		}
+		words += float64(e.Words)
+		minutes += m
+		sessions++
+	}
+
+	if sessions == 0 {
+		return 0, false
+	}
+	return words / minutes, true
+}
@@ -0,0 +1,244 @@
+package history
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/settings"
+)
+
+func entry(t *testing.T, when, command, refString string) Entry {
+	tm, err := time.Parse(time.RFC3339, when)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ref.ParseRange(refString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Entry{Time: tm, Command: command, Range: *r, Translation: "ESV", Duration: 90 * time.Second}
+}
+
+func TestAppendLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bible-history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state", "history.jsonl")
+	if entries, err := Load(path); err != nil || len(entries) != 0 {
+		t.Errorf("Load(missing) -> %v, %v", entries, err)
+	}
+
+	want := []Entry{
+		entry(t, "2026-10-18T07:00:00Z", "read", "John 3"),
+		entry(t, "2026-10-19T07:00:00Z", "play", "Psalm 23:1-4"),
+	}
+	want[0].Words = 871
+	for _, e := range want {
+		if err := Append(path, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Load -> %d entries, wanted %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || got[i].Range != want[i].Range ||
+			got[i].Command != want[i].Command || got[i].Duration != want[i].Duration || got[i].Words != want[i].Words {
+			t.Errorf("entry %d -> %+v, wanted %+v", i, got[i], want[i])
+		}
+	}
+
+	if err := Save(path, want[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Load(path); err != nil || len(got) != 1 || got[0].Range != want[1].Range {
+		t.Errorf("Load after Save -> %+v, %v", got, err)
+	}
+
+	if _, err := Read(strings.NewReader("{\"ref\": \"John 3\"}\nnot json\n")); err == nil {
+		t.Errorf("expected an error for a bad line")
+	}
+}
+
+// An entry appended while the log is being replaced is added after it
+func TestAppendWaitsForSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bible-history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "history.jsonl")
+	lock, err := settings.LockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := entry(t, "2026-10-19T07:00:00Z", "read", "John 4")
+	done := make(chan error)
+	go func() { done <- Append(path, e) }()
+	select {
+	case err := <-done:
+		t.Fatalf("Append didn't wait for the lock: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := Save(path, []Entry{entry(t, "2026-10-18T07:00:00Z", "read", "John 3")}); err != nil {
+		t.Fatal(err)
+	}
+	lock.Unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(path)
+	if err != nil || len(got) != 2 {
+		t.Errorf("Load -> %+v, %v; wanted both entries", got, err)
+	}
+}
+
+func TestSpeed(t *testing.T) {
+	timed := func(command string, words int, d time.Duration) Entry {
+		e := entry(t, "2026-10-18T07:00:00Z", command, "John 3")
+		e.Words, e.Duration = words, d
+		return e
+	}
+
+	entries := []Entry{
+		timed("read", 600, 3*time.Minute),
+		timed("read", 900, 3*time.Minute),
+		timed("read", 0, 3*time.Minute),
+		// Piped to a file in no time at all
+		timed("read", 800, time.Second),
+		timed("play", 450, 3*time.Minute),
+	}
+
+	if wpm, ok := Speed(entries, "read"); !ok || wpm != 250 {
+		t.Errorf("Speed(read) -> %v, %v, wanted 250", wpm, ok)
+	}
+	if wpm, ok := Speed(entries, "play"); !ok || wpm != 150 {
+		t.Errorf("Speed(play) -> %v, %v, wanted 150", wpm, ok)
+	}
+	if _, ok := Speed(entries[2:4], "read"); ok {
+		t.Errorf("expected no speed without timed entries")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	since, _ := time.Parse(time.RFC3339, "2026-10-18T00:00:00Z")
+	entries := []Entry{
+		entry(t, "2026-10-17T07:00:00Z", "read", "John 3"),
+		entry(t, "2026-10-18T07:00:00Z", "play", "John 4"),
+		entry(t, "2026-10-18T08:00:00Z", "read", "Luke 24-John 1"),
+		entry(t, "2026-10-19T07:00:00Z", "read", "Romans 8"),
+	}
+
+	cases := []struct {
+		f    Filter
+		want int
+	}{
+		{Filter{}, 4},
+		{Filter{Since: since}, 3},
+		{Filter{Command: "read"}, 3},
+		{Filter{Books: []ref.Book{ref.John}}, 3},
+		{Filter{Books: []ref.Book{ref.John}, Command: "READ", Since: since}, 1},
+		{Filter{Books: []ref.Book{ref.Genesis}}, 0},
+	}
+
+	for _, c := range cases {
+		if got := c.f.Apply(entries); len(got) != c.want {
+			t.Errorf("(%+v).Apply -> %d entries, wanted %d", c.f, len(got), c.want)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2026-10-19T12:00:00Z")
+	cases := []struct {
+		in, out string
+	}{
+		{"2026-10-01", "2026-10-01T00:00:00Z"},
+		{"7d", "2026-10-12T12:00:00Z"},
+		{"2w", "2026-10-05T12:00:00Z"},
+		{"36h", "2026-10-18T00:00:00Z"},
+	}
+
+	for _, c := range cases {
+		got, err := ParseSince(c.in, now)
+		if err != nil {
+			t.Errorf("ParseSince(%q): %s", c.in, err)
+			continue
+		}
+		if got.Format(time.RFC3339) != c.out {
+			t.Errorf("ParseSince(%q) -> %v, wanted %v", c.in, got.Format(time.RFC3339), c.out)
+		}
+	}
+
+	if _, err := ParseSince("last week", now); err == nil {
+		t.Errorf("expected an error for %q", "last week")
+	}
+}
+
+func TestReadChapters(t *testing.T) {
+	read := ReadChapters([]Entry{
+		entry(t, "2026-10-18T07:00:00Z", "read", "John 3:16"),
+		entry(t, "2026-10-18T07:00:00Z", "read", "Luke 24-John 1"),
+		entry(t, "2026-10-18T07:00:00Z", "read", "Ruth"),
+	})
+
+	cases := []struct {
+		chapter string
+		read    bool
+	}{
+		{"John 3", false},
+		{"Luke 24", true},
+		{"John 1", true},
+		{"John 2", false},
+		{"Ruth 4", true},
+	}
+
+	for _, c := range cases {
+		r, _ := ref.Parse(c.chapter)
+		if read[*r] != c.read {
+			t.Errorf("ReadChapters()[%v] -> %v, wanted %v", c.chapter, read[*r], c.read)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	entries := []Entry{entry(t, "2026-10-18T07:00:00Z", "read", "1 Corinthians 13")}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, entries, "csv", "short"); err != nil {
+		t.Fatal(err)
+	}
+	want := "time,command,ref,translation,seconds\n2026-10-18T07:00:00Z,read,1 Cor 13,ESV,90.0\n"
+	if buf.String() != want {
+		t.Errorf("Write(csv) -> %q, wanted %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	Write(&buf, entries, "json", "full")
+	if got, err := Read(&buf); err != nil || len(got) != 1 || got[0].Range != entries[0].Range {
+		t.Errorf("Write(json) didn't round trip: %v, %v", got, err)
+	}
+
+	if err := Write(&buf, entries, "yaml", "full"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
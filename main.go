@@ -2,25 +2,41 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"code.google.com/p/portaudio-go/portaudio"
-	"github.com/BurntSushi/toml"
-	"github.com/PuerkitoBio/goquery"
 	"github.com/Wessie/audec/mp3"
 	"github.com/codegangsta/cli"
+	"github.com/dtjm/bible/annotation"
+	"github.com/dtjm/bible/backup"
+	"github.com/dtjm/bible/cursor"
+	"github.com/dtjm/bible/history"
+	"github.com/dtjm/bible/memorize"
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/plan"
+	"github.com/dtjm/bible/provider"
+	"github.com/dtjm/bible/random"
 	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/render"
+	"github.com/dtjm/bible/settings"
+	"github.com/dtjm/bible/stats"
+	"github.com/dtjm/bible/term"
+	"github.com/dtjm/bible/tui"
+	"github.com/dtjm/bible/votd"
 	"github.com/facebookgo/counting"
 	"github.com/fatih/color"
 )
@@ -30,56 +46,457 @@ const (
 	version    = "0.0.5"
 )
 
-var (
-	configFile = os.Getenv("HOME") + "/.bible"
-)
-
+// config is the configuration, along with the helpers commands use it for
 type config struct {
-	Bookmarks map[string]string `toml:"bookmarks"`
+	*settings.Config
+}
+
+// translation returns the translation given by the --translation flag, or
+// the configured one
+func (c *config) translation(ctx *cli.Context) string {
+	if t := ctx.String("translation"); t != "" {
+		return t
+	}
+
+	return c.Translation
+}
+
+// setBookmark saves a reference under name
+func (c *config) setBookmark(name, refString string) error {
+	return c.Update(func(s *settings.Config) error {
+		s.SetBookmark(name, refString)
+		return nil
+	})
 }
 
-func readConfig() *config {
-	c := config{Bookmarks: make(map[string]string)}
-	_, err := os.Stat(configFile)
+// markRead records refString as the last passage read, once it has been
+// read. If it was read from a cursor, the cursor is moved on, unless another
+// process has moved it on already, so that running "read next" in two
+// terminals at once can't skip a chapter. If it was the last reading of a
+// day of a plan, the day is marked done.
+func (c *config) markRead(refString string, from *reading) error {
+	r, err := ref.Parse(refString)
+	if err != nil {
+		return err
+	}
+
+	return c.Update(func(s *settings.Config) error {
+		if from != nil && from.cursor != "" {
+			s.AdvanceBookmark(from.cursor, from.at, from.after)
+		}
+		if from != nil && from.lastOfDay {
+			if pr, ok := s.Plans[from.plan]; ok {
+				pr.MarkDone(from.day, true)
+				s.Plans[from.plan] = pr
+			}
+		}
+		s.SetBookmark("last", r.String())
+		return nil
+	})
+}
 
-	if os.IsNotExist(err) {
-		log.Printf("config file does not exist, creating %q", configFile)
-		c.Bookmarks["next"] = "Genesis 1"
-		return &c
+// confirmNext reports whether to ask before moving a cursor on, from the
+// --confirm flag or the config
+func (c *config) confirmNext(ctx *cli.Context) bool {
+	if ctx.IsSet("confirm") {
+		return ctx.Bool("confirm")
 	}
 
-	_, err = toml.DecodeFile(configFile, &c)
+	return c.ConfirmNext
+}
+
+// finished asks whether the reader has finished a passage. Pressing Enter
+// means yes. It is false if there is no terminal to ask on.
+func finished(refString string) bool {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("can't ask whether %s is finished: %s", refString, err)
+		return false
+	}
+	defer tty.Close()
+
+	fmt.Fprintf(tty, "Finished %s? [Y/n] ", refString)
+	answer, _ := bufio.NewReader(tty).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "", "y", "yes":
+		return true
 	}
 
-	return &c
+	return false
 }
 
-func (c *config) write() error {
-	f, err := os.Create(configFile)
+// fatal prints err to stderr and exits. The log is discarded without
+// --verbose, so errors the reader has to see go through here.
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// historyFile returns the path of the reading history
+func historyFile() string {
+	return filepath.Join(settings.StateDir(), "history.jsonl")
+}
+
+// recordHistory adds a passage read with command to the history, with the
+// number of words in it if they were counted. Failing to record it is logged
+// but doesn't stop the command.
+func (c *config) recordHistory(command, refString, translation string, started time.Time, words int) {
+	r, err := ref.ParseRange(refString)
+	if err == nil {
+		err = history.Append(historyFile(), history.Entry{
+			Time:        started,
+			Command:     command,
+			Range:       *r,
+			Translation: translation,
+			Duration:    time.Since(started),
+			Words:       words,
+		})
+	}
 	if err != nil {
-		return err
+		log.Printf("Error recording history: %s", err)
+	}
+}
+
+// countWords returns the number of words in the text of a passage
+func countWords(p *passage.Passage) int {
+	n := 0
+	for _, v := range p.Verses {
+		n += len(strings.Fields(v.Text))
 	}
-	defer f.Close()
 
-	enc := toml.NewEncoder(f)
-	return enc.Encode(c)
+	return n
 }
 
-func nextRef(s string) string {
-	r, err := ref.Parse(s)
+// formatRef writes a reference in the configured style, or as it is if it
+// can't be parsed
+func (c *config) formatRef(refString string) string {
+	r, err := ref.ParseRange(refString)
 	if err != nil {
-		log.Fatal(err)
+		return refString
+	}
+
+	return r.Format(c.RefStyle)
+}
+
+// readOptions returns the rendering options from the config file, overridden
+// by any flags given on the command line
+func (c *config) readOptions(ctx *cli.Context) passage.Options {
+	opts := c.Read
+	bools := map[string]*bool{
+		"verse-numbers":      &opts.VerseNumbers,
+		"headings":           &opts.Headings,
+		"subheadings":        &opts.Subheadings,
+		"footnotes":          &opts.Footnotes,
+		"passage-references": &opts.References,
+		"copyright":          &opts.Copyright,
+	}
+	for name, b := range bools {
+		if ctx.IsSet(name) {
+			*b = ctx.Bool(name)
+		}
+	}
+
+	if ctx.IsSet("line-length") {
+		opts.LineLength = ctx.Int("line-length")
+	}
+
+	return opts
+}
+
+// fetchPassage resolves the provider for a translation spec and fetches the
+// passage from it
+func (c *config) fetchPassage(translation, refString string, opts passage.Options) (*passage.Passage, error) {
+	r, err := ref.ParseRange(refString)
+	if err != nil {
+		return nil, err
+	}
+
+	p, t, err := provider.Resolve(translation, c.Providers)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetching %s (%s) from %s", r, t, p.Name())
+	return p.Passage(provider.Query{Range: *r, Translation: t, Options: opts})
+}
+
+// fetchPassages fetches the same passage in several translations at once,
+// returning them in the order the translations were given
+func (c *config) fetchPassages(translations []string, refString string, opts passage.Options) ([]*passage.Passage, error) {
+	passages := make([]*passage.Passage, len(translations))
+	errs := make([]error, len(translations))
+
+	var wg sync.WaitGroup
+	for i, t := range translations {
+		wg.Add(1)
+		go func(i int, t string) {
+			defer wg.Done()
+			passages[i], errs[i] = c.fetchPassage(strings.TrimSpace(t), refString, opts)
+		}(i, t)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return passages, nil
+}
+
+// fetchContext fetches a passage in each translation, widened by context
+// verses on either side and, with paragraph, out to the paragraphs around it.
+// When the passage is widened, the verses asked for are put in focus.
+func (c *config) fetchContext(translations []string, refString string, opts passage.Options, context int, paragraph bool) ([]*passage.Passage, error) {
+	if context <= 0 && !paragraph {
+		return c.fetchPassages(translations, refString, opts)
+	}
+
+	want, err := ref.ParseRange(refString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Paragraphs can only be found in the text, so fetch whole chapters and
+	// cut them down afterwards
+	fetch := want
+	if context > 0 {
+		fetch = fetch.Context(context)
+	}
+	if paragraph {
+		fetch = fetch.Chapters()
+	}
+
+	passages, err := c.fetchPassages(translations, fetch.String(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if paragraph {
+		para := passages[0].Paragraph(*want)
+		if context > 0 {
+			para = *para.Context(context)
+		}
+		for i := range passages {
+			passages[i] = passages[i].Slice(para)
+		}
+	}
+
+	for _, p := range passages {
+		p.Focus(*want)
+	}
+
+	return passages, nil
+}
+
+// writePassages writes the same passage in one or more translations. In
+// parallel, text is set side by side in columns that fit in width; other
+// formats get each translation in turn.
+func writePassages(w io.Writer, passages []*passage.Passage, format string, opts passage.Options, parallel bool, width int) error {
+	if parallel && format == "text" {
+		return render.Parallel(w, passages, width)
+	}
+
+	for _, p := range passages {
+		if err := render.Write(w, format, p, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// randomFilter builds the filter for random from its flags. Each flag given
+// narrows the choice further.
+func (c *config) randomFilter(ctx *cli.Context) (random.Filter, error) {
+	var f random.Filter
+	narrowed := false
+	narrow := func(books []ref.Book) {
+		allowed := make(map[ref.Book]bool)
+		for _, b := range f.Books {
+			allowed[b] = true
+		}
+
+		var kept []ref.Book
+		for _, b := range books {
+			if !narrowed || allowed[b] {
+				kept = append(kept, b)
+			}
+		}
+		f.Books, narrowed = kept, true
+	}
+
+	if t := ctx.String("testament"); t != "" {
+		books, err := ref.Testament(t)
+		if err != nil {
+			return f, err
+		}
+		narrow(books)
+	}
+
+	if groups := ctx.String("group"); groups != "" {
+		var books []ref.Book
+		for _, name := range strings.Split(groups, ",") {
+			group, err := ref.Group(strings.TrimSpace(name))
+			if err != nil {
+				return f, err
+			}
+			books = append(books, group...)
+		}
+		narrow(books)
+	}
+
+	if names := ctx.String("books"); names != "" {
+		var books []ref.Book
+		for _, name := range strings.Split(names, ",") {
+			r, err := ref.Parse(strings.TrimSpace(name))
+			if err != nil {
+				return f, err
+			}
+			books = append(books, r.Book())
+		}
+		narrow(books)
+	}
+
+	if narrowed && len(f.Books) == 0 {
+		return f, random.ErrNoneLeft
+	}
+
+	if ctx.Bool("unread") {
+		read, err := c.readChapters()
+		if err != nil {
+			return f, err
+		}
+		f.Read = read
+	}
+
+	return f, nil
+}
+
+// readChapters returns a function reporting whether a chapter has been read:
+// either it is before the "next" bookmark, or the history shows it was read
+// in full
+func (c *config) readChapters() (func(chapter *ref.Ref) bool, error) {
+	entries, err := history.Load(historyFile())
+	if err != nil {
+		return nil, err
 	}
+	read := history.ReadChapters(entries)
+	next, err := ref.Parse(c.Bookmark("next"))
+
+	return func(chapter *ref.Ref) bool {
+		return read[*chapter] || err == nil && chapter.Less(next)
+	}, nil
+}
+
+// play plays a reading of a passage, printing the text alongside, and then
+// records it as read
+func (c *config) play(ctx *cli.Context, from *reading, started time.Time) {
+	refString := from.read
+
+	var wg sync.WaitGroup
+	var words int
+	wg.Add(1)
+	go func() {
+		p, err := c.fetchPassage("ESV", refString, c.Read)
+		if err != nil {
+			log.Fatal(err)
+		}
+		words = countWords(p)
+
+		if err := render.Text(os.Stdout, p, c.Read); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print("\n")
+		wg.Done()
+	}()
+
+	wg.Add(1)
+	go func() {
+		portaudio.Initialize()
+		defer portaudio.Terminate()
+
+		query := url.Values{
+			"key":           {"IP"},
+			"output-format": {"mp3"},
+			"passage":       {refString},
+		}
+
+		resp, err := http.Get(esvBaseURL + "/passageQuery?" +
+			query.Encode())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer resp.Body.Close()
+
+		countingReader := counting.NewReader(resp.Body)
+		mp3Dec, err := mp3.NewDecoder(countingReader)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// buf := bytes.NewBuffer(nil)
+		// n, err := io.Copy(buf, mp3Dec)
+		// if err != nil {
+		//	 log.Fatal(err)
+		// }
+		// log.Printf("Copied %d bytes into buffer", n)
+		contentLength, err := strconv.Atoi(resp.Header.Get("Content-Length"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		mp3Stream := mp3Stream{
+			done:       make(chan struct{}),
+			counter:    countingReader,
+			br:         bufio.NewReader(mp3Dec),
+			totalBytes: contentLength,
+		}
+
+		outDevice, err := portaudio.DefaultOutputDevice()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		paStream, err := portaudio.OpenStream(portaudio.StreamParameters{
+			Input: portaudio.StreamDeviceParameters{},
+			Output: portaudio.StreamDeviceParameters{
+				Device:   outDevice,
+				Channels: 1,
+				Latency:  0,
+			},
+			SampleRate:      88200,
+			FramesPerBuffer: 16384,
+		}, mp3Stream.ProcessAudio)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		paStream.Start()
+		<-mp3Stream.done
+		paStream.Stop()
+		wg.Done()
+	}()
 
-	// nextRef := r.NextChapter()
-	return r.NextChapter().String()
+	wg.Wait()
+
+	// Only once the reading has been heard to the end
+	if q := from.question(); q != "" && c.confirmNext(ctx) && !finished(q) {
+		from = nil
+	}
+	if err := c.markRead(refString, from); err != nil {
+		log.Fatal(err)
+	}
+	c.recordHistory("play", refString, "ESV", started, words)
 }
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	conf := readConfig()
+	var conf *config
+	var configPath string
 
 	app := cli.NewApp()
 	app.Name = "bible"
@@ -90,14 +507,34 @@ func main() {
 
 	app.Flags = []cli.Flag{
 		cli.BoolFlag{Name: "verbose", Usage: "enable verbose logging"},
+		cli.StringFlag{Name: "config", Usage: "read the config from this file instead of " + settings.Path()},
 	}
 
+	app.EnableBashCompletion = true
+
 	app.Before = func(c *cli.Context) error {
 		if c.Bool("verbose") {
 			log.SetOutput(os.Stderr)
 		} else {
 			log.SetOutput(ioutil.Discard)
 		}
+
+		configPath = c.GlobalString("config")
+		if configPath == "" {
+			configPath = settings.Path()
+		}
+
+		// The config command works on the file as it is, so it mustn't be
+		// upgraded by loading it first
+		if c.Args().First() == "config" {
+			return nil
+		}
+
+		s, err := settings.Load(configPath)
+		if err != nil {
+			return err
+		}
+		conf = &config{s}
 		return nil
 	}
 
@@ -106,203 +543,1405 @@ func main() {
 			Name:      "read",
 			ShortName: "r",
 			Usage:     "Read a passage",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "translation, t",
+					Usage: "translation to read, optionally prefixed with a provider, e.g. KJV or apibible:NIV; defaults to the configured translation",
+				},
+				cli.StringFlag{
+					Name:  "parallel, p",
+					Usage: "comma-separated translations to read side by side, e.g. ESV,KJV",
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "text",
+					Usage: "output format: " + strings.Join(render.Formats, ", "),
+				},
+				cli.BoolFlag{Name: "verse-numbers", Usage: "include verse numbers"},
+				cli.BoolFlag{Name: "headings", Usage: "include section headings"},
+				cli.BoolFlag{Name: "subheadings", Usage: "include subheadings"},
+				cli.BoolFlag{Name: "footnotes", Usage: "include footnotes"},
+				cli.BoolFlag{Name: "passage-references", Usage: "include the reference before the passage"},
+				cli.BoolFlag{Name: "copyright", Usage: "include the translation's copyright line"},
+				cli.IntFlag{Name: "line-length", Usage: "wrap lines at this column, 0 for no wrapping"},
+				cli.BoolFlag{Name: "no-pager", Usage: "don't page long passages"},
+				cli.IntFlag{Name: "context, C", Usage: "include this many verses either side, marking the verses asked for"},
+				cli.BoolFlag{Name: "paragraph", Usage: "include the whole paragraph or section, marking the verses asked for"},
+				cli.StringFlag{Name: "file", Usage: "read references from a file, one entry per line; \"bible read -\" reads them from stdin"},
+				cli.IntFlag{Name: "jobs, j", Value: 4, Usage: "fetch this many entries of a batch at once"},
+				cli.StringFlag{Name: "advance, a", Usage: "read from this bookmark and move it on, as \"read next\" does with the next bookmark"},
+				cli.BoolFlag{Name: "confirm", Usage: "with next, today or --advance, ask whether you finished each passage before moving the bookmark on"},
+				cli.Float64Flag{Name: "minutes", Usage: "with next, today or --advance, read for about this long at your usual speed instead of by the bookmark's step"},
+				cli.BoolFlag{Name: "no-notes", Usage: "leave out your notes and highlights"},
+			},
+			BashComplete: func(c *cli.Context) {
+				completeRef(c, "next", "today", "plan:today", "plan:catchup")
+			},
 			Action: func(c *cli.Context) {
+				started := time.Now()
+				opts := conf.readOptions(c)
+				format := c.String("format")
+				translations := []string{conf.translation(c)}
+				parallel := c.String("parallel") != ""
+				if parallel {
+					// Headings and footnotes can't be lined up verse by verse
+					opts.Headings, opts.Subheadings, opts.Footnotes = false, false, false
+					translations = strings.Split(c.String("parallel"), ",")
+				}
+
+				width := opts.LineLength
+				if width == 0 {
+					width = term.Width(os.Stdout)
+				}
+
+				fetch := func(refString string) ([]*passage.Passage, error) {
+					passages, err := conf.fetchContext(translations, refString, opts, c.Int("context"), c.Bool("paragraph"))
+					if err == nil && !c.Bool("no-notes") {
+						annotate(passages)
+					}
+					return passages, err
+				}
+
+				if c.String("file") != "" || len(c.Args()) == 1 && c.Args()[0] == "-" {
+					in := os.Stdin
+					if c.String("file") != "" {
+						f, err := os.Open(c.String("file"))
+						if err != nil {
+							log.Fatal(err)
+						}
+						defer f.Close()
+						in = f
+					}
+
+					entries, err := readBatch(in)
+					if err != nil {
+						log.Fatal(err)
+					}
+
+					fetchBatch(entries, c.Int("jobs"), fetch)
+					ok, err := writeBatch(os.Stdout, entries, format, func(w io.Writer, passages []*passage.Passage) error {
+						return writePassages(w, passages, format, opts, parallel, width)
+					})
+					if err != nil {
+						log.Fatal(err)
+					}
+					for _, e := range entries {
+						if e.err != nil {
+							continue
+						}
+						for _, refString := range e.refs {
+							conf.recordHistory("read", refString, strings.Join(translations, ","), started, 0)
+						}
+					}
+					if !ok {
+						os.Exit(1)
+					}
+					return
+				}
+
 				var refString = strings.Join([]string(c.Args()), " ")
-				if strings.ToLower(refString) == "next" {
-					refString = conf.Bookmarks["next"]
-					conf.Bookmarks["next"] = nextRef(refString)
-					log.Printf("next:\t%s", refString)
+				readings, err := conf.readings(c, refString, translations[0])
+				if err != nil {
+					fatal(err)
 				}
 
-				if refString == "" {
+				refStrings := []string{refString}
+				if readings != nil {
+					if len(readings) == 0 {
+						fmt.Fprintf(os.Stderr, "There is nothing to read for %s\n", refString)
+						os.Exit(1)
+					}
+					refStrings = nil
+					for _, r := range readings {
+						refStrings = append(refStrings, r.read)
+					}
+					// Say which passage is which
+					if len(readings) > 1 {
+						opts.References = true
+					}
+				}
+
+				if refString == "" && readings == nil {
 					cli.ShowCommandHelp(c, c.Command.Name)
 					return
 				}
 
-				query := url.Values{
-					"key":                        {"IP"},
-					"output-format":              {"plain-text"},
-					"passage":                    {refString},
-					"include-headings":           {"0"},
-					"include-subheadings":        {"0"},
-					"include-passage-references": {"0"},
-					"include-verse-numbers":      {"0"},
-					"include-footnotes":          {"0"},
+				tty := !parallel && format == "text" && term.IsTerminal(os.Stdout)
+				buf := bytes.NewBuffer(nil)
+				words := make([]int, len(refStrings))
+				for i, refString := range refStrings {
+					passages, err := fetch(refString)
+					if err != nil {
+						log.Fatal(err)
+					}
+					words[i] = countWords(passages[0])
+
+					if i > 0 {
+						buf.WriteString(render.Separator(format))
+					}
+					if tty {
+						err = render.Terminal(buf, passages[0], opts, width, term.ColorEnabled(os.Stdout))
+					} else {
+						err = writePassages(buf, passages, format, opts, parallel, width)
+					}
+					if err != nil {
+						log.Fatal(err)
+					}
 				}
 
-				resp, err := http.Get(esvBaseURL + "/passageQuery?" + query.Encode())
+				if tty && !c.Bool("no-pager") {
+					err = term.Page(buf.String(), os.Stdout, os.Stdin)
+				} else {
+					_, err = buf.WriteTo(os.Stdout)
+				}
 				if err != nil {
 					log.Fatal(err)
 				}
+				if format == "text" {
+					fmt.Print("\n")
+				}
 
-				io.Copy(os.Stdout, resp.Body)
-				resp.Body.Close()
-				fmt.Print("\n\n")
+				// Only a single passage paged through in a terminal tells how
+				// fast it was read
+				if !tty || len(refStrings) > 1 {
+					words = make([]int, len(refStrings))
+				}
+				for i, refString := range refStrings {
+					var from *reading
+					if readings != nil {
+						from = readings[i]
+						if q := from.question(); q != "" && conf.confirmNext(c) && !finished(q) {
+							from = nil
+						}
+					}
+					if err := conf.markRead(refString, from); err != nil {
+						log.Fatal(err)
+					}
+					conf.recordHistory("read", refString, strings.Join(translations, ","), started, words[i])
+				}
+			},
+		},
+
+		{
+			Name:  "cite",
+			Usage: "Quote a passage with a citation for papers and slides",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "translation, t",
+					Usage: "translation to quote, optionally prefixed with a provider, e.g. KJV or apibible:NIV; defaults to the configured translation",
+				},
+				cli.StringFlag{
+					Name:  "style, s",
+					Value: "sbl",
+					Usage: "citation style: " + strings.Join(render.CitationStyles, ", "),
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "text",
+					Usage: "output format: " + strings.Join(render.CitationFormats, ", "),
+				},
+				cli.BoolFlag{Name: "block", Usage: "set the passage apart as a block quotation"},
+			},
+			BashComplete: func(c *cli.Context) {
+				completeRef(c)
+			},
+			Action: func(c *cli.Context) {
+				refString := strings.Join([]string(c.Args()), " ")
+				if refString == "" {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return
+				}
+
+				p, err := conf.fetchPassage(conf.translation(c), refString, passage.Options{})
+				if err != nil {
+					log.Fatal(err)
+				}
 
-				parsedRef, err := ref.Parse(refString)
+				err = render.Cite(os.Stdout, p, render.Citation{
+					Style:  c.String("style"),
+					Block:  c.Bool("block"),
+					Format: c.String("format"),
+				})
 				if err != nil {
 					log.Fatal(err)
 				}
-				conf.Bookmarks["last"] = parsedRef.String()
-				conf.write()
 			},
 		},
 
 		{
-			Name:      "mark",
-			ShortName: "m",
-			Usage:     "Bookmark a passage",
+			Name:  "votd",
+			Usage: "Show the verse of the day",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "translation, t",
+					Usage: "translation to read, optionally prefixed with a provider, e.g. KJV or apibible:NIV; defaults to the configured translation",
+				},
+				cli.StringFlag{Name: "date", Usage: "show the verse for another day, e.g. 2026-10-18"},
+				cli.BoolFlag{Name: "canon", Usage: "choose from the whole Bible rather than a list of well-loved verses"},
+				cli.BoolFlag{Name: "short, s", Usage: "print a single line, e.g. for a shell prompt"},
+				cli.IntFlag{Name: "width, w", Usage: "with --short, cut the line to this many characters"},
+			},
 			Action: func(c *cli.Context) {
-				if len(c.Args()) == 0 {
-					for m, r := range conf.Bookmarks {
-						fmt.Printf("%s:\t%s\n", m, r)
+				date := time.Now()
+				if c.String("date") != "" {
+					var err error
+					date, err = time.Parse(votd.DateFormat, c.String("date"))
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Error parsing date %q, expected YYYY-MM-DD\n", c.String("date"))
+						os.Exit(1)
 					}
-					return
 				}
 
-				if len(c.Args()) == 1 {
-					mark := c.Args()[0]
-					if r, ok := conf.Bookmarks[mark]; ok {
-						fmt.Printf("%s:\t%s\n", mark, r)
-					} else {
-						log.Printf("You don't have a bookmark called %q", mark)
-					}
+				p, err := conf.verseOfTheDay(date, conf.translation(c), c.Bool("canon"))
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				if c.Bool("short") {
+					fmt.Println(oneLine(p, c.Int("width"), conf.RefStyle))
 					return
 				}
 
-				mark := c.Args()[0]
-				var refString = strings.Join([]string(c.Args()[1:]), " ")
-				r, err := ref.Parse(refString)
+				opts := conf.Read
+				opts.References = true
+				if term.IsTerminal(os.Stdout) {
+					err = render.Terminal(os.Stdout, p, opts, term.Width(os.Stdout), term.ColorEnabled(os.Stdout))
+				} else {
+					err = render.Text(os.Stdout, p, opts)
+				}
 				if err != nil {
 					log.Fatal(err)
 				}
-
-				conf.Bookmarks[mark] = r.String()
-				conf.write()
 			},
 		},
 
 		{
-			Name:      "play",
-			ShortName: "p",
-			Usage:     "Play a reading of a passage",
+			Name:  "random",
+			Usage: "Read a verse, chapter or section chosen at random",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "translation, t",
+					Usage: "translation to read, optionally prefixed with a provider, e.g. KJV or apibible:NIV; defaults to the configured translation",
+				},
+				cli.StringFlag{Name: "unit, u", Value: "verse", Usage: "what to pick: verse, chapter or pericope"},
+				cli.StringFlag{Name: "testament", Usage: "pick from the old or new testament"},
+				cli.StringFlag{Name: "group, g", Usage: "pick from these comma-separated groups of books: " + strings.Join(ref.GroupNames(), ", ")},
+				cli.StringFlag{Name: "books, b", Usage: "pick from these comma-separated books, e.g. Gen,Ps,John"},
+				cli.BoolFlag{Name: "unread", Usage: "pick from chapters you haven't read yet"},
+				cli.IntFlag{Name: "seed", Usage: "seed the choice, to pick the same passage again"},
+				cli.BoolFlag{Name: "ref-only", Usage: "print the reference without fetching the passage"},
+			},
 			Action: func(c *cli.Context) {
+				filter, err := conf.randomFilter(c)
+				if err != nil {
+					log.Fatal(err)
+				}
 
-				var refString = strings.Join([]string(c.Args()), " ")
-				if strings.ToLower(refString) == "next" {
-					refString = conf.Bookmarks["next"]
-					conf.Bookmarks["next"] = nextRef(refString)
-					log.Printf("next:\t%s", refString)
+				seed := time.Now().UnixNano()
+				if c.IsSet("seed") {
+					seed = int64(c.Int("seed"))
 				}
+				rng := rand.New(rand.NewSource(seed))
 
-				if refString == "" {
-					cli.ShowCommandHelp(c, c.Command.Name)
-					return
+				var picked *ref.Ref
+				switch c.String("unit") {
+				case "verse", "pericope":
+					picked, err = random.Verse(rng, filter)
+				case "chapter":
+					picked, err = random.Chapter(rng, filter)
+				default:
+					fmt.Fprintf(os.Stderr, "Unknown unit %q, expected verse, chapter or pericope\n", c.String("unit"))
+					os.Exit(1)
+				}
+				if err != nil {
+					log.Fatal(err)
 				}
 
-				var wg sync.WaitGroup
-				wg.Add(1)
-				go func() {
-					query := url.Values{
-						"key":                        {"IP"},
-						"output-format":              {"plain-text"},
-						"passage":                    {refString},
-						"include-headings":           {"0"},
-						"include-subheadings":        {"0"},
-						"include-passage-references": {"0"},
-						"include-verse-numbers":      {"0"},
-						"include-footnotes":          {"0"},
+				refString := picked.String()
+				var p *passage.Passage
+				if c.String("unit") == "pericope" {
+					// The section around the verse can only be found in the text
+					chapter := ref.New(picked.Book(), picked.Chapter(), 0)
+					p, err = conf.fetchPassage(conf.translation(c), chapter.String(), conf.Read)
+					if err == nil {
+						p = p.Slice(p.Paragraph(ref.Range{Start: *picked, End: *picked}))
+						refString = p.Range.String()
 					}
+				} else if !c.Bool("ref-only") {
+					p, err = conf.fetchPassage(conf.translation(c), refString, conf.Read)
+				}
+				if err != nil {
+					log.Fatal(err)
+				}
 
-					resp, err := http.Get(esvBaseURL + "/passageQuery?" +
-						query.Encode())
+				if c.Bool("ref-only") {
+					r, err := ref.ParseRange(refString)
 					if err != nil {
 						log.Fatal(err)
 					}
+					fmt.Println(r.Format(conf.RefStyle))
+					return
+				}
 
-					io.Copy(os.Stdout, resp.Body)
-					resp.Body.Close()
-					fmt.Print("\n\n")
+				opts := conf.Read
+				opts.References = true
+				if term.IsTerminal(os.Stdout) {
+					err = render.Terminal(os.Stdout, p, opts, term.Width(os.Stdout), term.ColorEnabled(os.Stdout))
+				} else {
+					err = render.Text(os.Stdout, p, opts)
+				}
+				if err != nil {
+					log.Fatal(err)
+				}
+			},
+		},
 
-					parsedRef, err := ref.Parse(refString)
+		{
+			Name:  "history",
+			Usage: "List the passages you have read",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "since, s", Usage: "only what was read since a date or a time ago, e.g. 2026-10-01 or 7d"},
+				cli.StringFlag{Name: "book, b", Usage: "only passages in these books, comma-separated, e.g. John,Rom"},
+				cli.StringFlag{Name: "command, c", Usage: "only what was read with this command, read or play"},
+				cli.IntFlag{Name: "limit, n", Usage: "only the most recent entries"},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "text",
+					Usage: "output format: " + strings.Join(history.Formats, ", "),
+				},
+			},
+			Action: func(c *cli.Context) {
+				f := history.Filter{Command: c.String("command")}
+				if since := c.String("since"); since != "" {
+					t, err := history.ParseSince(since, time.Now())
 					if err != nil {
 						log.Fatal(err)
 					}
-					conf.Bookmarks["last"] = parsedRef.String()
-					conf.write()
-					wg.Done()
-				}()
+					f.Since = t
+				}
+				if names := c.String("book"); names != "" {
+					for _, name := range strings.Split(names, ",") {
+						r, err := ref.Parse(strings.TrimSpace(name))
+						if err != nil {
+							log.Fatal(err)
+						}
+						f.Books = append(f.Books, r.Book())
+					}
+				}
 
-				wg.Add(1)
-				go func() {
-					portaudio.Initialize()
-					defer portaudio.Terminate()
+				entries, err := history.Load(historyFile())
+				if err != nil {
+					log.Fatal(err)
+				}
+				entries = f.Apply(entries)
+				if n := c.Int("limit"); n > 0 && n < len(entries) {
+					entries = entries[len(entries)-n:]
+				}
 
-					query := url.Values{
-						"key":           {"IP"},
-						"output-format": {"mp3"},
-						"passage":       {refString},
-					}
+				if err := history.Write(os.Stdout, entries, c.String("format"), conf.RefStyle); err != nil {
+					log.Fatal(err)
+				}
+			},
+		},
+
+		{
+			Name:  "note",
+			Usage: "List your notes on passages, or add, edit and delete them",
+			Action: func(c *cli.Context) {
+				s, err := annotation.Load(annotationsFile())
+				if err != nil {
+					fatal(err)
+				}
+				if err := conf.printAnnotations(os.Stdout, notesOnly(s.List(nil))); err != nil {
+					fatal(err)
+				}
+			},
+			Subcommands: []cli.Command{
+				{
+					Name:  "add",
+					Usage: "Add a note on a passage: bible note add REFERENCE TEXT",
+					BashComplete: func(c *cli.Context) {
+						completeRef(c)
+					},
+					Action: func(c *cli.Context) {
+						args := c.Args()
+						if len(args) < 2 {
+							fmt.Fprintln(os.Stderr, "Usage: bible note add REFERENCE TEXT")
+							os.Exit(1)
+						}
+						r, err := ref.ParseRange(strings.Join(args[:len(args)-1], " "))
+						if err != nil {
+							fatal(err)
+						}
+
+						var id int
+						err = annotation.Update(annotationsFile(), func(s *annotation.Store) error {
+							id = s.AddNote(r, args[len(args)-1], time.Now())
+							return nil
+						})
+						if err != nil {
+							fatal(err)
+						}
+						fmt.Printf("Added note %d on %s\n", id, r.Format(conf.RefStyle))
+					},
+				},
+				{
+					Name:  "list",
+					Usage: "List your notes, or those on a passage: bible note list [REFERENCE]",
+					BashComplete: func(c *cli.Context) {
+						completeRef(c)
+					},
+					Action: func(c *cli.Context) {
+						s, err := annotation.Load(annotationsFile())
+						if err != nil {
+							fatal(err)
+						}
+
+						list := s.List(nil)
+						if len(c.Args()) > 0 {
+							r, err := ref.ParseRange(strings.Join(c.Args(), " "))
+							if err != nil {
+								fatal(err)
+							}
+							list = s.List(r)
+						}
+						if err := conf.printAnnotations(os.Stdout, notesOnly(list)); err != nil {
+							fatal(err)
+						}
+					},
+				},
+				{
+					Name:  "edit",
+					Usage: "Change the text of a note, or edit it in $EDITOR without TEXT: bible note edit NUMBER [TEXT]",
+					Action: func(c *cli.Context) {
+						id, err := strconv.Atoi(c.Args().First())
+						if err != nil {
+							fmt.Fprintln(os.Stderr, "Usage: bible note edit NUMBER [TEXT]")
+							os.Exit(1)
+						}
+
+						text := strings.Join(c.Args().Tail(), " ")
+						if text == "" {
+							s, err := annotation.Load(annotationsFile())
+							if err != nil {
+								fatal(err)
+							}
+							n, err := s.Note(id)
+							if err != nil {
+								fatal(err)
+							}
+							if text, err = editText(n.Text); err != nil {
+								fatal(err)
+							}
+						}
+
+						err = annotation.Update(annotationsFile(), func(s *annotation.Store) error {
+							return s.EditNote(id, text, time.Now())
+						})
+						if err != nil {
+							fatal(err)
+						}
+					},
+				},
+				{
+					Name:      "delete",
+					ShortName: "rm",
+					Usage:     "Delete a note: bible note delete NUMBER",
+					Action: func(c *cli.Context) {
+						id, err := strconv.Atoi(c.Args().First())
+						if err != nil {
+							fmt.Fprintln(os.Stderr, "Usage: bible note delete NUMBER")
+							os.Exit(1)
+						}
+
+						err = annotation.Update(annotationsFile(), func(s *annotation.Store) error {
+							return s.DeleteNote(id)
+						})
+						if err != nil {
+							fatal(err)
+						}
+					},
+				},
+			},
+		},
 
-					resp, err := http.Get(esvBaseURL + "/passageQuery?" +
-						query.Encode())
+		{
+			Name:  "highlight",
+			Usage: "Highlight a passage, or list your highlights",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "color, c",
+					Value: "yellow",
+					Usage: "colour to highlight in: " + strings.Join(annotation.Colors, ", "),
+				},
+				cli.BoolFlag{Name: "clear", Usage: "take the highlight off the passage"},
+			},
+			BashComplete: func(c *cli.Context) {
+				completeRef(c)
+			},
+			Action: func(c *cli.Context) {
+				if len(c.Args()) == 0 {
+					s, err := annotation.Load(annotationsFile())
 					if err != nil {
-						log.Fatal(err)
+						fatal(err)
+					}
+
+					var list []annotation.Annotation
+					for _, a := range s.List(nil) {
+						if a.Highlight != nil {
+							list = append(list, a)
+						}
 					}
+					if err := conf.printAnnotations(os.Stdout, list); err != nil {
+						fatal(err)
+					}
+					return
+				}
 
-					defer resp.Body.Close()
+				r, err := ref.ParseRange(strings.Join(c.Args(), " "))
+				if err != nil {
+					fatal(err)
+				}
+				color := strings.ToLower(c.String("color"))
+				if c.Bool("clear") {
+					color = ""
+				}
 
-					countingReader := counting.NewReader(resp.Body)
-					mp3Dec, err := mp3.NewDecoder(countingReader)
-					if err != nil {
-						log.Fatal(err)
+				err = annotation.Update(annotationsFile(), func(s *annotation.Store) error {
+					return s.Highlight(r, color, time.Now())
+				})
+				if err != nil {
+					fatal(err)
+				}
+			},
+		},
+
+		{
+			Name:  "memorize",
+			Usage: "Learn passages by heart, reviewing each when it is due",
+			Action: func(c *cli.Context) {
+				d, err := memorize.Load(memoryFile())
+				if err != nil {
+					fatal(err)
+				}
+				if err := conf.printCards(os.Stdout, d); err != nil {
+					fatal(err)
+				}
+			},
+			Subcommands: []cli.Command{
+				{
+					Name:  "add",
+					Usage: "Start memorizing a passage: bible memorize add REFERENCE",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "translation, t", Usage: "translation to memorize; defaults to the configured translation"},
+					},
+					BashComplete: func(c *cli.Context) {
+						completeRef(c)
+					},
+					Action: func(c *cli.Context) {
+						r, err := ref.ParseRange(strings.Join(c.Args(), " "))
+						if err != nil {
+							fatal(err)
+						}
+						translation := conf.translation(c)
+						text, err := conf.memorizeText(translation, r.String())
+						if err != nil {
+							fatal(err)
+						}
+
+						err = memorize.Update(memoryFile(), func(d *memorize.Deck) error {
+							return d.Add(r, memorize.NewCard(translation, text, time.Now()))
+						})
+						if err != nil {
+							fatal(err)
+						}
+						fmt.Printf("Memorizing %s; bible memorize review to start\n", r.Format(conf.RefStyle))
+					},
+				},
+				{
+					Name:  "review",
+					Usage: "Review the passages due today",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "mode, m", Usage: "how to be prompted: " + strings.Join(memorize.Modes, ", ") + "; by default hints for new passages, then cloze, then recite"},
+						cli.IntFlag{Name: "limit, n", Usage: "review at most this many passages"},
+					},
+					Action: func(c *cli.Context) {
+						mode := strings.ToLower(c.String("mode"))
+						if mode != "" {
+							if err := memorize.CheckMode(mode); err != nil {
+								fatal(err)
+							}
+						}
+
+						if err := conf.review(os.Stdin, os.Stdout, mode, c.Int("limit"), term.ColorEnabled(os.Stdout), time.Now()); err != nil {
+							fatal(err)
+						}
+					},
+				},
+				{
+					Name:  "list",
+					Usage: "List the passages being memorized and when each is due",
+					Action: func(c *cli.Context) {
+						d, err := memorize.Load(memoryFile())
+						if err != nil {
+							fatal(err)
+						}
+						if err := conf.printCards(os.Stdout, d); err != nil {
+							fatal(err)
+						}
+					},
+				},
+				{
+					Name:      "remove",
+					ShortName: "rm",
+					Usage:     "Stop memorizing a passage: bible memorize remove REFERENCE",
+					Action: func(c *cli.Context) {
+						r, err := ref.ParseRange(strings.Join(c.Args(), " "))
+						if err != nil {
+							fatal(err)
+						}
+
+						err = memorize.Update(memoryFile(), func(d *memorize.Deck) error {
+							if _, ok := d.Cards[r.String()]; !ok {
+								return fmt.Errorf("You aren't memorizing %s", r.String())
+							}
+							delete(d.Cards, r.String())
+							return nil
+						})
+						if err != nil {
+							fatal(err)
+						}
+					},
+				},
+			},
+		},
+
+		{
+			Name:  "stats",
+			Usage: "Show reading streaks and how much of the Bible you have read",
+			Flags: []cli.Flag{
+				cli.IntFlag{Name: "weeks, w", Value: 8, Usage: "show what was read in each of this many weeks"},
+				cli.StringFlag{Name: "command, c", Usage: "only count what was read with this command, read or play"},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "text",
+					Usage: "output format: " + strings.Join(stats.Formats, ", "),
+				},
+				cli.BoolFlag{Name: "no-pager", Usage: "don't page the output"},
+			},
+			Action: func(c *cli.Context) {
+				entries, err := history.Load(historyFile())
+				if err != nil {
+					log.Fatal(err)
+				}
+				entries = history.Filter{Command: c.String("command")}.Apply(entries)
+				s := stats.Compute(entries, time.Now(), c.Int("weeks"))
+
+				d, err := memorize.Load(memoryFile())
+				if err != nil {
+					fatal(err)
+				}
+				if len(d.Cards) > 0 {
+					s.Memory = &stats.Memory{Cards: len(d.Cards), Due: len(d.Due(time.Now()))}
+				}
+
+				tty := c.String("format") == "text" && term.IsTerminal(os.Stdout)
+				buf := bytes.NewBuffer(nil)
+				if err := stats.Write(buf, s, c.String("format"), term.Width(os.Stdout), term.ColorEnabled(os.Stdout)); err != nil {
+					log.Fatal(err)
+				}
+				if tty && !c.Bool("no-pager") {
+					err = term.Page(buf.String(), os.Stdout, os.Stdin)
+				} else {
+					_, err = buf.WriteTo(os.Stdout)
+				}
+				if err != nil {
+					log.Fatal(err)
+				}
+			},
+		},
+
+		{
+			Name:  "export",
+			Usage: "Back up your bookmarks, history, notes, highlights, plans and memory cards to a JSON file",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "out, o", Usage: "write the backup to this file instead of the standard output"},
+			},
+			Action: func(c *cli.Context) {
+				b, err := conf.exportBackup(time.Now())
+				if err != nil {
+					fatal(err)
+				}
+
+				var buf bytes.Buffer
+				if err := b.Write(&buf); err != nil {
+					fatal(err)
+				}
+				out := c.String("out")
+				if out == "" || out == "-" {
+					if _, err := buf.WriteTo(os.Stdout); err != nil {
+						fatal(err)
 					}
+					return
+				}
+				if err := settings.WriteData(out, buf.Bytes()); err != nil {
+					fatal(err)
+				}
+				fmt.Printf("Exported %s to %s\n", b.Summary(), out)
+			},
+		},
+
+		{
+			Name:  "import",
+			Usage: "Restore a backup made with bible export, merging it with what you have: bible import FILE",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "strategy, s",
+					Value: "union",
+					Usage: "how to merge: " + strings.Join(backup.Strategies, ", "),
+				},
+			},
+			Action: func(c *cli.Context) {
+				path := c.Args().First()
+				if path == "" {
+					fmt.Fprintln(os.Stderr, "Which backup? bible import FILE")
+					os.Exit(1)
+				}
+				if err := backup.CheckStrategy(c.String("strategy")); err != nil {
+					fatal(err)
+				}
 
-					// buf := bytes.NewBuffer(nil)
-					// n, err := io.Copy(buf, mp3Dec)
-					// if err != nil {
-					// 	log.Fatal(err)
-					// }
-					// log.Printf("Copied %d bytes into buffer", n)
-					contentLength, err := strconv.Atoi(resp.Header.Get("Content-Length"))
+				var in io.Reader = os.Stdin
+				if path != "-" {
+					f, err := os.Open(path)
 					if err != nil {
+						fatal(err)
+					}
+					defer f.Close()
+					in = f
+				}
+				b, err := backup.Read(in)
+				if err != nil {
+					fatal(err)
+				}
+
+				merged, err := conf.importBackup(b, c.String("strategy"), time.Now())
+				if err != nil {
+					fatal(err)
+				}
+				fmt.Printf("Imported %s with %s; you now have %s\n", path, c.String("strategy"), merged.Summary())
+			},
+		},
+
+		{
+			Name:  "next",
+			Usage: "Move a cursor on without reading, or see or undo where it goes: bible next [bookmark]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "peek", Usage: "show what reading the cursor will read, or with --undo where it would go back to, without moving it"},
+				cli.BoolFlag{Name: "undo", Usage: "move the cursor back to where it was before it last moved on"},
+			},
+			BashComplete: func(c *cli.Context) {
+				for _, name := range conf.Cursors() {
+					fmt.Println(name)
+				}
+			},
+			Action: func(c *cli.Context) {
+				name := c.Args().First()
+				if name == "" {
+					name = "next"
+				}
+				if !c.Bool("undo") && !conf.IsCursor(name) {
+					fmt.Fprintf(os.Stderr, "You don't have a cursor called %q; make one with bible mark %s REF --step 1ch\n", name, name)
+					os.Exit(1)
+				}
+
+				var r *reading
+				if !c.Bool("undo") {
+					var err error
+					if r, err = conf.cursorReading(name, conf.Translation, 0); err != nil {
 						log.Fatal(err)
 					}
+				}
+				if c.Bool("peek") && c.Bool("undo") {
+					previous, err := conf.PreviousBookmark(name)
+					if err != nil {
+						fatal(err)
+					}
+					fmt.Println(conf.formatRef(previous))
+					return
+				}
+				if c.Bool("peek") {
+					fmt.Println(conf.formatRef(r.read))
+					return
+				}
 
-					mp3Stream := mp3Stream{
-						done:       make(chan struct{}),
-						counter:    countingReader,
-						br:         bufio.NewReader(mp3Dec),
-						totalBytes: contentLength,
+				var now string
+				err := conf.Update(func(s *settings.Config) error {
+					if c.Bool("undo") {
+						var err error
+						now, err = s.UndoBookmark(name)
+						return err
 					}
 
-					outDevice, err := portaudio.DefaultOutputDevice()
-					if err != nil {
-						log.Fatal(err)
+					if !s.AdvanceBookmark(name, r.at, r.after) {
+						log.Printf("%s has already been moved on", name)
 					}
+					now = s.Bookmark(name)
+					return nil
+				})
+				if err != nil {
+					fatal(err)
+				}
+				fmt.Printf("%s:\t%s\n", name, conf.formatRef(now))
+			},
+		},
 
-					paStream, err := portaudio.OpenStream(portaudio.StreamParameters{
-						Input: portaudio.StreamDeviceParameters{},
-						Output: portaudio.StreamDeviceParameters{
-							Device:   outDevice,
-							Channels: 1,
-							Latency:  0,
-						},
-						SampleRate:      88200,
-						FramesPerBuffer: 16384,
-					}, mp3Stream.ProcessAudio)
+		{
+			Name:      "mark",
+			ShortName: "m",
+			Usage:     "Bookmark a passage, or list, show, edit, rename or delete bookmarks",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "delete, d", Usage: "delete the named bookmarks"},
+				cli.BoolFlag{Name: "rename", Usage: "rename a bookmark: bible mark --rename old new"},
+				cli.StringFlag{Name: "note, n", Usage: "describe the bookmark"},
+				cli.StringFlag{Name: "tag, t", Usage: "comma-separated tags to add to the bookmark"},
+				cli.StringFlag{Name: "untag", Usage: "comma-separated tags to take off the bookmark"},
+				cli.StringFlag{Name: "sort, s", Value: "canon", Usage: "list bookmarks in order: " + strings.Join(settings.BookmarkSorts, ", ")},
+				cli.StringFlag{Name: "tagged", Usage: "list only the bookmarks with this tag"},
+				cli.StringFlag{Name: "step", Usage: "make the bookmark a cursor that moves on this much as it is read, e.g. \"2 chapters\", \"10 verses\" or \"pericope\"; \"\" makes it a plain bookmark again"},
+				cli.StringFlag{Name: "loop", Usage: "make a cursor go round this range, e.g. \"Proverbs 1-31\"; \"\" takes the loop off"},
+			},
+			BashComplete: func(c *cli.Context) {
+				conf.completeBookmarks(c)
+			},
+			Action: func(c *cli.Context) {
+				args := c.Args()
+				edit := c.IsSet("note") || c.IsSet("tag") || c.IsSet("untag") || c.IsSet("step") || c.IsSet("loop")
+				step, err := checkCursor(c.String("step"), c.String("loop"))
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				switch {
+				case c.Bool("delete") && len(args) > 0:
+					err = conf.Update(func(s *settings.Config) error {
+						for _, name := range args {
+							if err := s.DeleteBookmark(name); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+
+				case c.Bool("rename") && len(args) == 2:
+					err = conf.Update(func(s *settings.Config) error {
+						return s.RenameBookmark(args[0], args[1])
+					})
+
+				case c.Bool("delete") || c.Bool("rename"):
+					cli.ShowCommandHelp(c, c.Command.Name)
+
+				case len(args) == 0:
+					var names []string
+					names, err = conf.BookmarkNames(c.String("sort"), c.String("tagged"))
+					if err == nil {
+						err = conf.printBookmarks(os.Stdout, names)
+					}
+
+				case len(args) == 1 && !edit:
+					if b, ok := conf.Bookmarks[args[0]]; ok {
+						conf.printBookmark(os.Stdout, args[0], b)
+					} else {
+						log.Printf("You don't have a bookmark called %q", args[0])
+					}
+
+				default:
+					mark := args[0]
+					var r *ref.Ref
+					if len(args) > 1 {
+						if r, err = ref.Parse(strings.Join(args[1:], " ")); err != nil {
+							log.Fatal(err)
+						}
+					}
+
+					err = conf.Update(func(s *settings.Config) error {
+						if r != nil {
+							s.SetBookmark(mark, r.String())
+						}
+						if !edit {
+							return nil
+						}
+						return s.EditBookmark(mark, func(b *settings.Bookmark) {
+							if c.IsSet("note") {
+								b.Description = c.String("note")
+							}
+							editTags(b, c.String("tag"), c.String("untag"))
+							if c.IsSet("step") {
+								b.Step = step
+							}
+							if c.IsSet("loop") {
+								b.Loop = c.String("loop")
+								// A loop is only any use to a cursor
+								if b.Loop != "" && b.Step == "" && mark != "next" {
+									b.Step = cursor.DefaultStep.String()
+								}
+							}
+						})
+					})
+				}
+				if err != nil {
+					log.Fatal(err)
+				}
+			},
+		},
+
+		{
+			Name:      "play",
+			ShortName: "p",
+			Usage:     "Play a reading of a passage",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "advance, a", Usage: "play from this bookmark and move it on, as \"play next\" does with the next bookmark"},
+				cli.BoolFlag{Name: "confirm", Usage: "with next or --advance, ask whether you finished the passage before moving the bookmark on"},
+				cli.Float64Flag{Name: "minutes", Usage: "with next or --advance, play for about this long instead of by the bookmark's step"},
+			},
+			BashComplete: func(c *cli.Context) {
+				completeRef(c, "next")
+			},
+			Action: func(c *cli.Context) {
+				started := time.Now()
+
+				var refString = strings.Join([]string(c.Args()), " ")
+				readings, err := conf.readings(c, refString, "ESV")
+				if err != nil {
+					fatal(err)
+				}
+				if readings == nil && refString != "" {
+					readings = []*reading{{read: refString}}
+				}
+
+				if len(readings) == 0 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return
+				}
+
+				for _, from := range readings {
+					conf.play(c, from, started)
+					started = time.Now()
+				}
+			},
+		},
+
+		{
+			Name:  "tui",
+			Usage: "Read interactively, a chapter at a time",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "translation, t",
+					Usage: "translation to read, optionally prefixed with a provider, e.g. KJV or apibible:NIV; defaults to the configured translation",
+				},
+			},
+			BashComplete: func(c *cli.Context) {
+				completeRef(c)
+			},
+			Action: func(c *cli.Context) {
+				if !term.IsTerminal(os.Stdin) || !term.IsTerminal(os.Stdout) {
+					fmt.Fprintln(os.Stderr, "bible tui needs a terminal")
+					os.Exit(1)
+				}
+
+				// Start where asked, or where the last session left off
+				refString := strings.Join([]string(c.Args()), " ")
+				for _, mark := range []string{"tui", "next"} {
+					if refString == "" {
+						refString = conf.Bookmark(mark)
+					}
+				}
+				if refString == "" {
+					refString = "Genesis 1"
+				}
+
+				start, err := ref.Parse(refString)
+				if err != nil {
+					log.Fatal(err)
+				}
+				chapter := start.Chapter()
+				if chapter == 0 {
+					chapter = 1
+				}
 
+				p, translation, err := provider.Resolve(conf.translation(c), conf.Providers)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				reader := tui.Reader{
+					Position: *ref.New(start.Book(), chapter, 0),
+					Options:  conf.Read,
+					Width:    term.Width(os.Stdout),
+					Height:   term.Height(os.Stdout),
+					Color:    term.ColorEnabled(os.Stdout),
+					Fetch: func(r ref.Range, opts passage.Options) (*passage.Passage, error) {
+						return p.Passage(provider.Query{Range: r, Translation: translation, Options: opts})
+					},
+					Bookmark: func(name string, r ref.Ref) error {
+						return conf.setBookmark(name, r.String())
+					},
+					Moved: func(r ref.Ref) {
+						conf.setBookmark("tui", r.String())
+					},
+				}
+				if s, ok := p.(provider.Searcher); ok {
+					reader.Search = s.Search
+				}
+
+				state, err := term.MakeRaw(os.Stdin)
+				if err != nil {
+					log.Fatal(err)
+				}
+				err = reader.Run(os.Stdin, os.Stdout)
+				term.Restore(os.Stdin, state)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				if err := conf.setBookmark("tui", reader.Position.String()); err != nil {
+					log.Fatal(err)
+				}
+			},
+		},
+
+		{
+			Name:  "completion",
+			Usage: "Print a script for bash or zsh to complete commands, bookmarks and books",
+			Action: func(c *cli.Context) {
+				if c.Args().First() == "zsh" {
+					fmt.Println("autoload -U compinit && compinit")
+					fmt.Println("autoload -U bashcompinit && bashcompinit")
+				}
+				fmt.Print(bashCompletion)
+			},
+		},
+
+		{
+			Name:  "plan",
+			Usage: "List reading plans, or start one and keep up with it",
+			Action: func(c *cli.Context) {
+				if err := conf.printPlans(os.Stdout, time.Now()); err != nil {
+					fatal(err)
+				}
+			},
+			Subcommands: []cli.Command{
+				{
+					Name:  "start",
+					Usage: "Start a plan today, or on another day: bible plan start NAME, or a plan file: bible plan start FILE.toml",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "date", Usage: "the day to read the first day's reading, e.g. 2027-01-01"},
+					},
+					BashComplete: func(c *cli.Context) {
+						fmt.Println(strings.Join(planNames(), "\n"))
+					},
+					Action: func(c *cli.Context) {
+						name := strings.ToLower(c.Args().First())
+						if _, err := os.Stat(c.Args().First()); err == nil {
+							// A plan file, to keep with the generated plans
+							p, err := readPlanFile(c.Args().First())
+							if err == nil {
+								err = conf.savePlan(p)
+							}
+							if err != nil {
+								fatal(err)
+							}
+							name = p.Name
+						}
+						if _, err := loadPlan(name); err != nil {
+							fatal(err)
+						}
+
+						start := time.Now()
+						if c.String("date") != "" {
+							var err error
+							if start, err = time.Parse(plan.DateFormat, c.String("date")); err != nil {
+								fmt.Fprintf(os.Stderr, "Error parsing date %q, expected YYYY-MM-DD\n", c.String("date"))
+								os.Exit(1)
+							}
+						}
+
+						err := conf.Update(func(s *settings.Config) error {
+							if _, ok := s.Plans[name]; ok {
+								return fmt.Errorf("You have already started the %s plan; bible plan stop %s first to start again", name, name)
+							}
+							s.Plans[name] = plan.Progress{Start: plan.Date(start)}
+							return nil
+						})
+						if err != nil {
+							fatal(err)
+						}
+					},
+				},
+				{
+					Name:  "generate",
+					Usage: "Make a plan that divides passages into even daily readings: bible plan generate Isaiah-Malachi --days 60",
+					Flags: []cli.Flag{
+						cli.IntFlag{Name: "days, d", Usage: "read it in this many days"},
+						cli.StringFlag{Name: "until", Usage: "read it by this date, e.g. 2027-04-04"},
+						cli.StringFlag{Name: "start", Usage: "with --until, the day to start on, e.g. 2027-02-17; defaults to today"},
+						cli.StringFlag{Name: "by", Value: "verses", Usage: "balance the days by the number of verses or words"},
+						cli.StringFlag{Name: "skip", Usage: "comma-separated weekdays with no reading, e.g. sat,sun"},
+						cli.StringFlag{Name: "name, n", Value: "custom", Usage: "the name to start the plan by"},
+						cli.StringFlag{Name: "title", Usage: "describe the plan"},
+						cli.StringFlag{Name: "out, o", Usage: "also write the plan to this .toml or .json file"},
+						cli.StringFlag{Name: "translation, t", Usage: "translation to count words and find sections in; defaults to the configured translation"},
+						cli.BoolFlag{Name: "offline", Usage: "don't fetch the text, balancing by verses and keeping to whole chapters alone"},
+						cli.IntFlag{Name: "jobs, j", Value: 4, Usage: "fetch this many chapters at once"},
+					},
+					Action: func(c *cli.Context) {
+						ranges, err := parsePlanRanges(strings.Join(c.Args(), " "))
+						if err != nil {
+							fatal(err)
+						}
+						skip, err := plan.ParseWeekdays(c.String("skip"))
+						if err != nil {
+							fatal(err)
+						}
+						p := &plan.Plan{Name: strings.ToLower(c.String("name")), Title: c.String("title"), Skip: skip}
+						if p.Title == "" {
+							p.Title = strings.Join(c.Args(), " ")
+						}
+
+						start := time.Now()
+						if c.String("start") != "" {
+							if start, err = time.Parse(plan.DateFormat, c.String("start")); err != nil {
+								fmt.Fprintf(os.Stderr, "Error parsing date %q, expected YYYY-MM-DD\n", c.String("start"))
+								os.Exit(1)
+							}
+						}
+						pr := &plan.Progress{Start: plan.Date(start)}
+
+						days := c.Int("days")
+						if c.String("until") != "" {
+							until, err := time.Parse(plan.DateFormat, c.String("until"))
+							if err != nil {
+								fmt.Fprintf(os.Stderr, "Error parsing date %q, expected YYYY-MM-DD\n", c.String("until"))
+								os.Exit(1)
+							}
+							days = pr.Day(p, until)
+						}
+						if days < 1 {
+							fmt.Fprintln(os.Stderr, "Give the number of days with --days, or a date after the start with --until")
+							os.Exit(1)
+						}
+
+						verses, err := conf.planVerses(ranges, c.String("by"), conf.translation(c), c.Bool("offline"), c.Int("jobs"))
+						if err != nil {
+							fatal(err)
+						}
+						if p.Days, err = plan.Generate(verses, days); err != nil {
+							fatal(err)
+						}
+
+						if err := conf.savePlan(p); err != nil {
+							fatal(err)
+						}
+						if c.String("out") != "" {
+							if err := writePlanFile(c.String("out"), p); err != nil {
+								fatal(err)
+							}
+						}
+
+						for day := range p.Days {
+							fmt.Printf("day %d, %s: %s\n", day+1, pr.Date(p, day+1).Format("Mon Jan 2"), conf.formatDay(p.Days[day]))
+						}
+						fmt.Printf("\nStart it with: bible plan start %s --date %s\n", p.Name, pr.Start.Format(plan.DateFormat))
+					},
+				},
+				{
+					Name:  "stop",
+					Usage: "Stop a plan, forgetting how far through it you are: bible plan stop NAME",
+					Action: func(c *cli.Context) {
+						err := conf.Update(func(s *settings.Config) error {
+							for _, name := range c.Args() {
+								if _, ok := s.Plans[strings.ToLower(name)]; !ok {
+									return fmt.Errorf("You haven't started the %s plan", name)
+								}
+								delete(s.Plans, strings.ToLower(name))
+							}
+							return nil
+						})
+						if err != nil {
+							fatal(err)
+						}
+					},
+				},
+				{
+					Name:  "today",
+					Usage: "Show today's readings from the plans you have started, or one of them",
+					Action: func(c *cli.Context) {
+						names, err := conf.startedPlans(c.Args().First())
+						if err != nil {
+							fatal(err)
+						}
+
+						now := time.Now()
+						for _, name := range names {
+							p, err := loadPlan(name)
+							if err != nil {
+								fatal(err)
+							}
+							pr := conf.Plans[name]
+							switch day := pr.Today(p, now); {
+							case day > 0:
+								conf.printDays(os.Stdout, p, []int{day})
+							case pr.Day(p, now) < 1:
+								fmt.Printf("%s starts on %s\n", name, pr.Start.Format(plan.DateFormat))
+							default:
+								fmt.Printf("%s has finished\n", name)
+							}
+						}
+					},
+				},
+				{
+					Name:  "mark",
+					Usage: "Mark today's readings, or another day's, as done",
+					Flags: []cli.Flag{
+						cli.IntFlag{Name: "day, d", Usage: "mark this day of the plan rather than today's"},
+						cli.BoolFlag{Name: "undo", Usage: "mark the day as not done"},
+					},
+					Action: func(c *cli.Context) {
+						names, err := conf.startedPlans(c.Args().First())
+						if err != nil {
+							fatal(err)
+						}
+
+						now := time.Now()
+						err = conf.Update(func(s *settings.Config) error {
+							for _, name := range names {
+								p, err := loadPlan(name)
+								if err != nil {
+									return err
+								}
+								pr := s.Plans[name]
+								day := pr.Today(p, now)
+								if c.IsSet("day") {
+									day = c.Int("day")
+								}
+								if day < 1 || !p.Monthly && day > len(p.Days) {
+									return fmt.Errorf("The %s plan has no day %d", name, day)
+								}
+								pr.MarkDone(day, !c.Bool("undo"))
+								s.Plans[name] = pr
+							}
+							return nil
+						})
+						if err != nil {
+							fatal(err)
+						}
+					},
+				},
+				{
+					Name:  "catchup",
+					Usage: "Show the readings you have missed, to read with bible read plan:catchup",
+					Action: func(c *cli.Context) {
+						names, err := conf.startedPlans(c.Args().First())
+						if err != nil {
+							fatal(err)
+						}
+
+						for _, name := range names {
+							p, err := loadPlan(name)
+							if err != nil {
+								fatal(err)
+							}
+							pr := conf.Plans[name]
+							conf.printDays(os.Stdout, p, pr.Missed(p, time.Now()))
+						}
+					},
+				},
+				{
+					Name:  "reschedule",
+					Usage: "Put off a plan by the days you have missed, so that the first one falls today",
+					Action: func(c *cli.Context) {
+						names, err := conf.startedPlans(c.Args().First())
+						if err != nil {
+							fatal(err)
+						}
+
+						now := time.Now()
+						var moved []string
+						err = conf.Update(func(s *settings.Config) error {
+							moved = nil
+							for _, name := range names {
+								p, err := loadPlan(name)
+								if err != nil {
+									return err
+								}
+								if p.Monthly {
+									moved = append(moved, fmt.Sprintf("%s follows the days of the month, so it can't be put off", name))
+									continue
+								}
+								pr := s.Plans[name]
+								day := pr.Reschedule(p, now)
+								s.Plans[name] = pr
+								moved = append(moved, fmt.Sprintf("%s: day %d is today, finishing on %s", name, day, pr.Start.AddDate(0, 0, len(p.Days)-1).Format(plan.DateFormat)))
+							}
+							return nil
+						})
+						if err != nil {
+							fatal(err)
+						}
+						fmt.Println(strings.Join(moved, "\n"))
+					},
+				},
+			},
+		},
+
+		{
+			Name:  "config",
+			Usage: "Manage the config file",
+			Subcommands: []cli.Command{
+				{
+					Name:  "migrate",
+					Usage: "Upgrade the config file to the current schema version",
+					Flags: []cli.Flag{
+						cli.BoolFlag{Name: "dry-run, n", Usage: "show what would change without changing it"},
+					},
+					Action: func(c *cli.Context) {
+						if err := migrateConfig(os.Stdout, configPath, c.Bool("dry-run")); err != nil {
+							log.Fatal(err)
+						}
+					},
+				},
+			},
+		},
+
+		{
+			Name:      "translations",
+			ShortName: "t",
+			Usage:     "List the translations each provider offers",
+			Action: func(c *cli.Context) {
+				names := provider.Names()
+				if len(c.Args()) > 0 {
+					names = c.Args()
+				}
+
+				headingStyle := color.New(color.Bold).Add(color.FgGreen)
+				for _, name := range names {
+					p, err := provider.New(name, conf.Providers[name])
 					if err != nil {
 						log.Fatal(err)
 					}
 
-					paStream.Start()
-					<-mp3Stream.done
-					paStream.Stop()
-					wg.Done()
-				}()
+					translations, err := p.Translations()
+					if err != nil {
+						fmt.Fprintln(os.Stderr, err)
+						continue
+					}
 
-				wg.Wait()
+					headingStyle.Println(name)
+					for _, t := range translations {
+						fmt.Printf("%s\t%s\t%s\n", t.ID, t.Name, t.Language)
+					}
+					fmt.Println()
+				}
 			},
 		},
 
@@ -317,38 +1956,29 @@ func main() {
 				}
 				var queryString = strings.Join([]string(c.Args()), " ")
 
-				query := url.Values{
-					"key":   {"IP"},
-					"words": {queryString},
-					// "q":                {queryString},
-					"search-text":      {"text"},
-					"results-per-page": {"100"},
-				}
-
-				resp, err := http.Get(esvBaseURL + "/query?" + query.Encode())
+				results, err := provider.NewESV(conf.Providers["esv"]).Search(queryString)
 				if err != nil {
 					log.Fatal(err)
 				}
-				defer resp.Body.Close()
 
-				doc, err := goquery.NewDocumentFromResponse(resp)
-				if err != nil {
-					log.Fatal(err)
+				printRef := color.New(color.Bold).Add(color.FgGreen).PrintFunc()
+				if !term.ColorEnabled(os.Stdout) {
+					printRef = func(a ...interface{}) { fmt.Print(a...) }
+				}
+				for _, r := range results {
+					printRef(r.Reference)
+					fmt.Println("\t", r.Text)
 				}
-				doc.Find("span.show-me").Remove()
-
-				refStyle := color.New(color.Bold).Add(color.FgGreen)
-				doc.Find("p.search-result").Each(func(_ int, s *goquery.Selection) {
-					refStyle.Print(s.Find("a").First().Remove().Text())
-					fmt.Println("\t", s.Text())
-				})
 
 				fmt.Print("\n\n")
 			},
 		},
 	}
 
-	app.Run(os.Args)
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
 
 type counter interface {
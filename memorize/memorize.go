@@ -0,0 +1,247 @@
+// Package memorize schedules passages to learn by heart with the SM-2
+// spaced repetition algorithm, and scores attempts to recite them.
+//
+// Cards are kept in a JSON file keyed by reference:
+//
+//	{
+//	  "version": 1,
+//	  "cards": {
+//	    "Psalm 119:11": {"translation": "ESV", "text": "I have stored up...",
+//	                     "added": "2026-10-19T07:30:00Z", "due": "2026-10-25",
+//	                     "interval": 6, "ease": 2.6, "reps": 2, "lapses": 0}
+//	  }
+//	}
+package memorize
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/settings"
+)
+
+// Version is the version of the layout of the file
+const Version = 1
+
+// DateFormat is how due dates are written
+const DateFormat = "2006-01-02"
+
+const (
+	// startEase is the ease of a new card
+	startEase = 2.5
+
+	// minEase keeps cards that are often forgotten from coming up every day
+	// for ever
+	minEase = 1.3
+)
+
+// Card is a passage being memorised
+type Card struct {
+	Translation string `json:"translation"`
+
+	// Text is the passage as it was when the card was added, which
+	// attempts are scored against
+	Text string `json:"text"`
+
+	Added    time.Time `json:"added"`
+	Reviewed time.Time `json:"reviewed,omitempty"`
+
+	// Due is the day the card is next to be reviewed, in DateFormat
+	Due string `json:"due"`
+
+	// Interval is the number of days between the last review and Due
+	Interval int `json:"interval"`
+
+	// Ease is how much the interval grows after each good review
+	Ease float64 `json:"ease"`
+
+	// Reps counts the reviews in a row that have been good enough, and
+	// Lapses the times the card has been forgotten after that
+	Reps   int `json:"reps"`
+	Lapses int `json:"lapses"`
+}
+
+// NewCard returns a card for text, due today
+func NewCard(translation, text string, now time.Time) Card {
+	return Card{
+		Translation: translation,
+		Text:        text,
+		Added:       now,
+		Due:         now.Format(DateFormat),
+		Ease:        startEase,
+	}
+}
+
+// IsDue reports whether the card is due for review on the day of now
+func (c *Card) IsDue(now time.Time) bool {
+	return c.Due <= now.Format(DateFormat)
+}
+
+// Review schedules the card after a review of the given quality, from 0
+// (forgotten completely) to 5 (perfect), as in SM-2. A quality below 3
+// starts the card again from a day's interval.
+func (c *Card) Review(quality int, now time.Time) {
+	if quality < 0 {
+		quality = 0
+	}
+	if quality > 5 {
+		quality = 5
+	}
+
+	if quality < 3 {
+		if c.Reps > 0 {
+			c.Lapses++
+		}
+		c.Reps, c.Interval = 0, 1
+	} else {
+		c.Reps++
+		switch c.Reps {
+		case 1:
+			c.Interval = 1
+		case 2:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Ceil(float64(c.Interval) * c.Ease))
+		}
+	}
+
+	q := float64(5 - quality)
+	c.Ease += 0.1 - q*(0.08+q*0.02)
+	if c.Ease < minEase {
+		c.Ease = minEase
+	}
+
+	c.Reviewed = now
+	c.Due = now.AddDate(0, 0, c.Interval).Format(DateFormat)
+}
+
+// Deck holds the cards by the reference of their passages, written out in
+// full
+type Deck struct {
+	Version int             `json:"version"`
+	Cards   map[string]Card `json:"cards"`
+}
+
+// New returns an empty deck
+func New() *Deck {
+	return &Deck{Version: Version, Cards: make(map[string]Card)}
+}
+
+// Load reads the deck at path. A missing file is an empty deck.
+func Load(path string) (*Deck, error) {
+	d := New()
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", path, err)
+	}
+	if d.Version > Version {
+		return nil, fmt.Errorf("%s was written by a newer version of bible (version %d, expected at most %d)", path, d.Version, Version)
+	}
+	d.Version = Version
+	if d.Cards == nil {
+		d.Cards = make(map[string]Card)
+	}
+	for key := range d.Cards {
+		if _, err := ref.ParseRange(key); err != nil {
+			return nil, fmt.Errorf("Error reading %s: %s", path, err)
+		}
+	}
+
+	return d, nil
+}
+
+// Save writes the deck to path, replacing the file in one step
+func (d *Deck) Save(path string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return settings.WriteData(path, append(data, '\n'))
+}
+
+// Update changes the deck at path as one transaction: it takes the lock on
+// the file, reads it, lets f change it and writes it back. Nothing is written
+// if f returns an error.
+func Update(path string, f func(d *Deck) error) error {
+	lock, err := settings.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	d, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := f(d); err != nil {
+		return err
+	}
+
+	return d.Save(path)
+}
+
+// Add adds a card for the passage r. It is an error if there is one for r
+// already.
+func (d *Deck) Add(r *ref.Range, c Card) error {
+	key := r.String()
+	if _, ok := d.Cards[key]; ok {
+		return fmt.Errorf("You are already memorizing %s", key)
+	}
+
+	d.Cards[key] = c
+	return nil
+}
+
+// Keys returns the references of the cards in the order their passages come
+// in the Bible
+func (d *Deck) Keys() []string {
+	type keyed struct {
+		key   string
+		start int
+	}
+	var keys []keyed
+	for key := range d.Cards {
+		r, _ := ref.ParseRange(key)
+		keys = append(keys, keyed{key, ref.Index(&r.Verses().Start)})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].start != keys[j].start {
+			return keys[i].start < keys[j].start
+		}
+		return keys[i].key < keys[j].key
+	})
+
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.key
+	}
+	return names
+}
+
+// Due returns the references of the cards due for review on the day of now,
+// those that have been due longest first
+func (d *Deck) Due(now time.Time) []string {
+	var due []string
+	for _, key := range d.Keys() {
+		if c := d.Cards[key]; c.IsDue(now) {
+			due = append(due, key)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return d.Cards[due[i]].Due < d.Cards[due[j]].Due })
+
+	return due
+}
@@ -0,0 +1,180 @@
+package memorize
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtjm/bible/ref"
+)
+
+var now = time.Date(2026, 10, 19, 7, 30, 0, 0, time.UTC)
+
+func TestReview(t *testing.T) {
+	cases := []struct {
+		quality  int
+		interval int
+		reps     int
+		due      string
+	}{
+		{4, 1, 1, "2026-10-20"},
+		{5, 6, 2, "2026-10-25"},
+		{4, 16, 3, "2026-11-04"},
+		{1, 1, 0, "2026-10-20"},
+		{3, 1, 1, "2026-10-20"},
+	}
+
+	c := NewCard("ESV", "Jesus wept.", now)
+	if !c.IsDue(now) || c.IsDue(now.AddDate(0, 0, -1)) {
+		t.Errorf("new card due %s", c.Due)
+	}
+
+	for i, want := range cases {
+		c.Review(want.quality, now)
+		if c.Interval != want.interval || c.Reps != want.reps || c.Due != want.due {
+			t.Errorf("review %d with quality %d -> interval %d, reps %d, due %s; wanted %d, %d, %s",
+				i, want.quality, c.Interval, c.Reps, c.Due, want.interval, want.reps, want.due)
+		}
+	}
+
+	if c.Lapses != 1 {
+		t.Errorf("Lapses -> %d, wanted 1", c.Lapses)
+	}
+	// 2.5, then +0, +0.1, +0, -0.54, -0.14
+	if c.Ease < 1.91 || c.Ease > 1.93 {
+		t.Errorf("Ease -> %v, wanted 1.92", c.Ease)
+	}
+
+	for i := 0; i < 10; i++ {
+		c.Review(0, now)
+	}
+	if c.Ease != minEase {
+		t.Errorf("Ease after forgetting -> %v, wanted %v", c.Ease, minEase)
+	}
+}
+
+func TestHints(t *testing.T) {
+	got := Hints("For God so loved the world, that he gave his only Son; “whoever believes” won’t perish.")
+	want := "F G s l t w, t h g h o S; “w b” w p."
+	if got != want {
+		t.Errorf("Hints -> %q, wanted %q", got, want)
+	}
+}
+
+func TestCloze(t *testing.T) {
+	text := "In the beginning was the Word, and the Word was with God — and the Word was God."
+	prompt, missing := Cloze(text, rand.New(rand.NewSource(1)))
+
+	if len(missing) != 6 {
+		t.Fatalf("Cloze -> %d words missing, wanted 6: %q", len(missing), missing)
+	}
+
+	// Filling in the blanks gives the text back
+	filled := prompt
+	for _, w := range missing {
+		filled = strings.Replace(filled, strings.Repeat("_", len(w)), w, 1)
+	}
+	if filled != text {
+		t.Errorf("Cloze(%q) -> %q with %q", text, prompt, missing)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		given string
+		diff  string
+		score float64
+	}{
+		{"for god so loved the world", "For God so loved the world,", 1},
+		{"For God loved the world", "For God [-so-] loved the world,", 5.0 / 6},
+		{"For God so much loved the whole world", "For God so {+much+} loved the {+whole+} world,", 6.0 / 8},
+		{"For God so loves the world", "For God so [-loved-] {+loves+} the world,", 5.0 / 6},
+		{"", "[-For-] [-God-] [-so-] [-loved-] [-the-] [-world,-]", 0},
+	}
+
+	for _, c := range cases {
+		ops := Diff("For God so loved the world,", c.given)
+		if diff := FormatDiff(ops, false); diff != c.diff {
+			t.Errorf("Diff(%q) -> %q, wanted %q", c.given, diff, c.diff)
+		}
+		if score := Score(ops); score != c.score {
+			t.Errorf("Score(%q) -> %v, wanted %v", c.given, score, c.score)
+		}
+	}
+}
+
+func TestQuality(t *testing.T) {
+	cases := []struct {
+		score float64
+		mode  string
+		want  int
+	}{
+		{1, "recite", 5},
+		{1, "cloze", 4},
+		{1, "hints", 3},
+		{0.92, "recite", 4},
+		{0.8, "recite", 3},
+		{0.6, "hints", 2},
+		{0.1, "recite", 1},
+		{0, "recite", 0},
+	}
+
+	for _, c := range cases {
+		if q := Quality(c.score, c.mode); q != c.want {
+			t.Errorf("Quality(%v, %s) -> %d, wanted %d", c.score, c.mode, q, c.want)
+		}
+	}
+}
+
+func TestDeck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bible-memorize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "memory.json")
+	err = Update(path, func(d *Deck) error {
+		for _, s := range []string{"Romans 8:28", "John 11:35", "Psalm 119:11"} {
+			r, _ := ref.ParseRange(s)
+			if err := d.Add(r, NewCard("ESV", s, now)); err != nil {
+				return err
+			}
+		}
+		r, _ := ref.ParseRange("John 11:35")
+		if err := d.Add(r, NewCard("ESV", "", now)); err == nil {
+			t.Errorf("expected an error adding a card twice")
+		}
+
+		c := d.Cards["John 11:35"]
+		c.Review(5, now)
+		d.Cards["John 11:35"] = c
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := strings.Join(d.Keys(), ", "); keys != "Psalm 119:11, John 11:35, Romans 8:28" {
+		t.Errorf("Keys -> %s", keys)
+	}
+	if due := strings.Join(d.Due(now), ", "); due != "Psalm 119:11, Romans 8:28" {
+		t.Errorf("Due(today) -> %s", due)
+	}
+	if due := d.Due(now.AddDate(0, 0, 1)); len(due) != 3 {
+		t.Errorf("Due(tomorrow) -> %s", due)
+	}
+
+	ioutil.WriteFile(path, []byte(`{"version": 2}`), 0600)
+	if _, err := Load(path); err == nil {
+		t.Errorf("expected an error for a newer version")
+	}
+}
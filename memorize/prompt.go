@@ -0,0 +1,252 @@
+package memorize
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// Modes are the ways a card can be reviewed: with the first letter of each
+// word as a hint, with some words left out to fill in, or reciting it all
+var Modes = []string{"hints", "cloze", "recite"}
+
+// maxQuality is the best review each mode can give, since hints make a
+// passage easier to recall
+var maxQuality = map[string]int{"hints": 3, "cloze": 4, "recite": 5}
+
+// ModeFor returns the mode to review a card in: hints while it is new, then
+// cloze, then reciting it all once it has been recalled a few times
+func ModeFor(c *Card) string {
+	switch {
+	case c.Reps == 0:
+		return "hints"
+	case c.Reps < 3:
+		return "cloze"
+	}
+
+	return "recite"
+}
+
+// CheckMode checks that mode is one of Modes
+func CheckMode(mode string) error {
+	for _, m := range Modes {
+		if m == mode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unknown mode %q, expected one of %q", mode, Modes)
+}
+
+// Hints returns text with each word cut down to its first letter, keeping
+// the punctuation, e.g. "F G s l t w," for "For God so loved the world,"
+func Hints(text string) string {
+	words := strings.Fields(text)
+	for i, w := range words {
+		var b bytes.Buffer
+		letter := false
+		for _, r := range w {
+			switch {
+			case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+				if r != '\'' && r != '’' {
+					b.WriteRune(r)
+				}
+			case !letter:
+				b.WriteRune(r)
+				letter = true
+			}
+		}
+		words[i] = b.String()
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Cloze leaves out about a third of the words of text, chosen by rng, and
+// returns the text with blanks in their place and the words left out
+func Cloze(text string, rng *rand.Rand) (string, []string) {
+	words := strings.Fields(text)
+	var candidates []int
+	for i, w := range words {
+		if strings.TrimFunc(w, notWordRune) != "" {
+			candidates = append(candidates, i)
+		}
+	}
+
+	blank := make(map[int]bool)
+	for _, i := range rng.Perm(len(candidates))[:(len(candidates)+2)/3] {
+		blank[candidates[i]] = true
+	}
+
+	var missing []string
+	for i, w := range words {
+		if blank[i] {
+			word := strings.TrimFunc(w, notWordRune)
+			missing = append(missing, word)
+			words[i] = strings.Replace(w, word, strings.Repeat("_", len([]rune(word))), 1)
+		}
+	}
+
+	return strings.Join(words, " "), missing
+}
+
+// Prompt returns what to show for a card reviewed in mode, and the words the
+// answer is scored against
+func Prompt(c *Card, mode string, rng *rand.Rand) (string, string) {
+	switch mode {
+	case "hints":
+		return Hints(c.Text), c.Text
+	case "cloze":
+		prompt, missing := Cloze(c.Text, rng)
+		return prompt, strings.Join(missing, " ")
+	}
+
+	return "", c.Text
+}
+
+// Op is a word of a diff between the words expected and those given
+type Op struct {
+	// Kind is '=' for a word that was right, '-' for one that was missed
+	// out and '+' for one that shouldn't be there
+	Kind rune
+	Word string
+}
+
+// Diff compares the words of an attempt at reciting a passage with the
+// expected ones, ignoring case and punctuation. Words in the result are as
+// they were written.
+func Diff(expected, given string) []Op {
+	a, b := strings.Fields(expected), strings.Fields(given)
+	na, nb := make([]string, len(a)), make([]string, len(b))
+	for i, w := range a {
+		na[i] = normalize(w)
+	}
+	for i, w := range b {
+		nb[i] = normalize(w)
+	}
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if na[i] == nb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []Op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && na[i] == nb[j]:
+			ops = append(ops, Op{'=', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, Op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, Op{'+', b[j]})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// Score returns how close an attempt was, from 0 to 1: the words that were
+// right out of the words expected or given, whichever is more
+func Score(ops []Op) float64 {
+	right, expected, given := 0, 0, 0
+	for _, op := range ops {
+		switch op.Kind {
+		case '=':
+			right++
+			expected++
+			given++
+		case '-':
+			expected++
+		case '+':
+			given++
+		}
+	}
+
+	if given > expected {
+		expected = given
+	}
+	if expected == 0 {
+		return 1
+	}
+	return float64(right) / float64(expected)
+}
+
+// Quality turns the score of a review in mode into an SM-2 quality
+func Quality(score float64, mode string) int {
+	var q int
+	switch {
+	case score >= 1:
+		q = 5
+	case score >= 0.9:
+		q = 4
+	case score >= 0.75:
+		q = 3
+	case score >= 0.5:
+		q = 2
+	case score > 0:
+		q = 1
+	}
+
+	if max, ok := maxQuality[mode]; ok && q > max {
+		q = max
+	}
+	return q
+}
+
+// FormatDiff writes a diff as words, with those missed out as [-word-] and
+// those that shouldn't be there as {+word+}. With color, those missed out are
+// in green and those that shouldn't be there are struck through in red.
+func FormatDiff(ops []Op, color bool) string {
+	words := make([]string, len(ops))
+	for i, op := range ops {
+		switch {
+		case op.Kind == '=':
+			words[i] = op.Word
+		case color && op.Kind == '-':
+			words[i] = "\x1b[32m" + op.Word + "\x1b[0m"
+		case color:
+			words[i] = "\x1b[31;9m" + op.Word + "\x1b[0m"
+		case op.Kind == '-':
+			words[i] = "[-" + op.Word + "-]"
+		default:
+			words[i] = "{+" + op.Word + "+}"
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// normalize returns a word in lower case without the punctuation around it
+func normalize(w string) string {
+	w = strings.Map(func(r rune) rune {
+		if r == '’' {
+			return '\''
+		}
+		return unicode.ToLower(r)
+	}, w)
+
+	return strings.TrimFunc(w, notWordRune)
+}
+
+func notWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
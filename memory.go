@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dtjm/bible/memorize"
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/settings"
+)
+
+// memoryFile returns the path of the passages being memorised
+func memoryFile() string {
+	return filepath.Join(settings.DataDir(), "memory.json")
+}
+
+// memorizeText fetches the text of a passage to memorise, as one line
+func (c *config) memorizeText(translation, refString string) (string, error) {
+	p, err := c.fetchPassage(translation, refString, c.Read)
+	if err != nil {
+		return "", err
+	}
+
+	var words []string
+	for _, v := range p.Verses {
+		words = append(words, strings.Fields(v.Text)...)
+	}
+	if len(words) == 0 {
+		return "", fmt.Errorf("There is no text for %s in %s", refString, translation)
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// printCards lists the passages being memorised, a line each, with when they
+// are next due
+func (c *config) printCards(w io.Writer, d *memorize.Deck) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	for _, key := range d.Keys() {
+		card := d.Cards[key]
+		r, _ := ref.ParseRange(key)
+		every := "new"
+		if card.Interval > 0 {
+			every = "every " + days(card.Interval)
+		}
+		fmt.Fprintf(tw, "%s\t%s\tdue %s\t%s\tease %.2f\n", r.Format(c.RefStyle), card.Translation, card.Due, every, card.Ease)
+	}
+	tw.Flush()
+
+	return writeColumns(w, buf.String())
+}
+
+// review goes through the cards due today, asking for each to be recalled in
+// mode, or the mode that suits the card if mode is empty, and scheduling it
+// by how well it was. It stops after limit cards, if limit is more than 0,
+// or at the end of the input. Each card is saved as soon as it is reviewed.
+func (c *config) review(in io.Reader, out io.Writer, mode string, limit int, color bool, now time.Time) error {
+	d, err := memorize.Load(memoryFile())
+	if err != nil {
+		return err
+	}
+
+	due := d.Due(now)
+	if len(due) == 0 {
+		fmt.Fprintln(out, "Nothing is due for review today.")
+		return nil
+	}
+	if limit > 0 && limit < len(due) {
+		due = due[:limit]
+	}
+
+	rng := rand.New(rand.NewSource(now.UnixNano()))
+	lines := bufio.NewReader(in)
+	for i, key := range due {
+		card := d.Cards[key]
+		m := mode
+		if m == "" {
+			m = memorize.ModeFor(&card)
+		}
+
+		r, _ := ref.ParseRange(key)
+		fmt.Fprintf(out, "(%d/%d) %s (%s)\n", i+1, len(due), r.Format(c.RefStyle), card.Translation)
+		prompt, answer := memorize.Prompt(&card, m, rng)
+		switch m {
+		case "hints":
+			fmt.Fprintf(out, "%s\nType the passage:\n", prompt)
+		case "cloze":
+			fmt.Fprintf(out, "%s\nType the missing words:\n", prompt)
+		default:
+			fmt.Fprintln(out, "Recite the passage:")
+		}
+
+		fmt.Fprint(out, "> ")
+		given, err := lines.ReadString('\n')
+		if err == io.EOF && given == "" {
+			fmt.Fprintln(out)
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		ops := memorize.Diff(answer, given)
+		score := memorize.Score(ops)
+		quality := memorize.Quality(score, m)
+		var interval int
+		err = memorize.Update(memoryFile(), func(d *memorize.Deck) error {
+			card, ok := d.Cards[key]
+			if !ok {
+				return fmt.Errorf("%s was taken out of the passages being memorized during the review", key)
+			}
+			card.Review(quality, now)
+			d.Cards[key] = card
+			interval = card.Interval
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "%s\n%.0f%% right; next review in %s\n\n", memorize.FormatDiff(ops, color), score*100, days(interval))
+	}
+
+	return nil
+}
+
+// days writes a number of days
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+
+	return fmt.Sprintf("%d days", n)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dtjm/bible/settings"
+)
+
+// migrateConfig upgrades the config file at path to the current schema
+// version, or with dryRun, describes how it would be upgraded
+func migrateConfig(w io.Writer, path string, dryRun bool) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Fprintf(w, "There is no config file at %s\n", path)
+		return nil
+	}
+
+	plan := settings.Migrate
+	if dryRun {
+		plan = settings.PlanMigration
+	}
+
+	m, err := plan(path)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		fmt.Fprintf(w, "%s is up to date (schema version %d)\n", path, settings.SchemaVersion)
+		return nil
+	}
+
+	verb := "Upgraded"
+	if dryRun {
+		verb = "Would upgrade"
+	}
+	fmt.Fprintf(w, "%s %s from schema version %d to %d:\n", verb, path, m.From, m.To)
+	for _, step := range m.Steps {
+		fmt.Fprintf(w, "  - %s\n", step)
+	}
+	fmt.Fprintf(w, "The original is kept as %s\n", m.Backup)
+
+	if dryRun {
+		fmt.Fprintln(w)
+		for _, line := range diffLines(string(m.Before), string(m.After)) {
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	return nil
+}
+
+// diffLines compares two texts line by line, returning every line prefixed
+// with "-" if it is only in a, "+" if it is only in b, or a space if it is in
+// both
+func diffLines(a, b string) []string {
+	x := strings.Split(strings.TrimRight(a, "\n"), "\n")
+	y := strings.Split(strings.TrimRight(b, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, " "+x[i])
+			i++
+			j++
+		case j == len(y) || i < len(x) && lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "-"+x[i])
+			i++
+		default:
+			lines = append(lines, "+"+y[j])
+			j++
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want string
+	}{
+		{"a\nb\nc\n", "a\nb\nc\n", " a| b| c"},
+		{"a\nc\n", "a\nb\nc\n", " a|+b| c"},
+		{"a\nb\nc\n", "a\nc", " a|-b| c"},
+		{"a\nb\n", "a\nc\n", " a|-b|+c"},
+		{"a\n", "b\na\nc\n", "+b| a|+c"},
+	}
+
+	for _, c := range cases {
+		if got := strings.Join(diffLines(c.a, c.b), "|"); got != c.want {
+			t.Errorf("diffLines(%q, %q) -> %q, wanted %q", c.a, c.b, got, c.want)
+		}
+	}
+}
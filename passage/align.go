@@ -0,0 +1,56 @@
+package passage
+
+import (
+	"sort"
+
+	"github.com/dtjm/bible/ref"
+)
+
+// Row is a single verse lined up across several passages
+type Row struct {
+	Ref ref.Ref
+
+	// Texts holds the text of the verse in each passage, in the order the
+	// passages were given, with "" where a passage lacks the verse
+	Texts []string
+
+	// Partial is true when the verse is missing from at least one passage,
+	// e.g. Romans 16:24, which modern translations omit
+	Partial bool
+
+	// Focus is true when the verse is in focus in any of the passages
+	Focus bool
+}
+
+// Align lines up the verses of several passages, usually the same reference in
+// different translations, in canonical order
+func Align(passages ...*Passage) []Row {
+	index := make(map[ref.Ref]int)
+	var rows []Row
+	for i, p := range passages {
+		for _, v := range p.Verses {
+			n, ok := index[v.Ref]
+			if !ok {
+				n = len(rows)
+				index[v.Ref] = n
+				rows = append(rows, Row{Ref: v.Ref, Texts: make([]string, len(passages))})
+			}
+			rows[n].Texts[i] = v.Text
+			rows[n].Focus = rows[n].Focus || v.Focus
+		}
+	}
+
+	for i := range rows {
+		for _, t := range rows[i].Texts {
+			if t == "" {
+				rows[i].Partial = true
+			}
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Ref.Less(&rows[j].Ref)
+	})
+
+	return rows
+}
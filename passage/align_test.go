@@ -0,0 +1,39 @@
+package passage
+
+import (
+	"testing"
+
+	"github.com/dtjm/bible/ref"
+)
+
+func TestAlign(t *testing.T) {
+	esv := &Passage{Translation: "ESV", Verses: []Verse{
+		{Ref: *ref.New(ref.Romans, 16, 23), Text: "Gaius greets you."},
+		{Ref: *ref.New(ref.Romans, 16, 25), Text: "Now to him who is able"},
+	}}
+	kjv := &Passage{Translation: "KJV", Verses: []Verse{
+		{Ref: *ref.New(ref.Romans, 16, 23), Text: "Gaius saluteth you."},
+		{Ref: *ref.New(ref.Romans, 16, 24), Text: "The grace of our Lord"},
+		{Ref: *ref.New(ref.Romans, 16, 25), Text: "Now to him that is of power"},
+	}}
+
+	rows := Align(esv, kjv)
+
+	expected := []Row{
+		{*ref.New(ref.Romans, 16, 23), []string{"Gaius greets you.", "Gaius saluteth you."}, false, false},
+		{*ref.New(ref.Romans, 16, 24), []string{"", "The grace of our Lord"}, true, false},
+		{*ref.New(ref.Romans, 16, 25), []string{"Now to him who is able", "Now to him that is of power"}, false, false},
+	}
+
+	if len(rows) != len(expected) {
+		t.Fatalf("got %d rows, wanted %d", len(rows), len(expected))
+	}
+
+	for i, e := range expected {
+		r := rows[i]
+		if r.Ref != e.Ref || r.Partial != e.Partial ||
+			r.Texts[0] != e.Texts[0] || r.Texts[1] != e.Texts[1] {
+			t.Errorf("row %d: got %+v, wanted %+v", i, r, e)
+		}
+	}
+}
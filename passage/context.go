@@ -0,0 +1,59 @@
+package passage
+
+import (
+	"github.com/dtjm/bible/ref"
+)
+
+// Focus marks the verses within r as the ones asked for
+func (p *Passage) Focus(r ref.Range) {
+	for i := range p.Verses {
+		p.Verses[i].Focus = r.Contains(&p.Verses[i].Ref)
+	}
+}
+
+// Paragraph returns the paragraphs of the passage that contain r. A paragraph
+// starts at a verse with a paragraph break or a heading, so where a provider
+// marks section headings this is the pericope. If the passage has no verses
+// in r, r is returned as it is.
+func (p *Passage) Paragraph(r ref.Range) ref.Range {
+	first, last := -1, -1
+	for i, v := range p.Verses {
+		if r.Contains(&v.Ref) {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return r
+	}
+
+	for first > 0 && !startsParagraph(p.Verses[first]) {
+		first--
+	}
+	for last+1 < len(p.Verses) && !startsParagraph(p.Verses[last+1]) {
+		last++
+	}
+
+	return ref.Range{Start: p.Verses[first].Ref, End: p.Verses[last].Ref}
+}
+
+func startsParagraph(v Verse) bool {
+	return v.Paragraph || len(v.Headings) > 0
+}
+
+// Slice returns the part of the passage within r
+func (p *Passage) Slice(r ref.Range) *Passage {
+	s := *p
+	s.Range = r
+	s.Reference = r.String()
+	s.Verses = nil
+	for _, v := range p.Verses {
+		if r.Contains(&v.Ref) {
+			s.Verses = append(s.Verses, v)
+		}
+	}
+
+	return &s
+}
@@ -0,0 +1,65 @@
+package passage
+
+import (
+	"testing"
+
+	"github.com/dtjm/bible/ref"
+)
+
+// mark4 is the start of Mark 4, with a heading at verse 1 and verse 10 and a
+// paragraph break at verse 3
+var mark4 = &Passage{Translation: "ESV", Verses: []Verse{
+	{Ref: *ref.New(ref.Mark, 4, 1), Text: "Again he began to teach", Headings: []Heading{{Level: 1, Text: "The Parable of the Sower"}}},
+	{Ref: *ref.New(ref.Mark, 4, 2), Text: "And he was teaching them"},
+	{Ref: *ref.New(ref.Mark, 4, 3), Text: "Listen!", Paragraph: true},
+	{Ref: *ref.New(ref.Mark, 4, 4), Text: "And as he sowed"},
+	{Ref: *ref.New(ref.Mark, 4, 5), Text: "Other seed fell"},
+	{Ref: *ref.New(ref.Mark, 4, 10), Text: "And when he was alone", Headings: []Heading{{Level: 1, Text: "The Purpose of the Parables"}}},
+	{Ref: *ref.New(ref.Mark, 4, 11), Text: "And he said to them"},
+}}
+
+func TestParagraph(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+	}{
+		{"Mark 4:4", "Mark 4:3-5"},
+		{"Mark 4:2", "Mark 4:1-2"},
+		{"Mark 4:2-3", "Mark 4:1-5"},
+		{"Mark 4:11", "Mark 4:10-11"},
+		{"Mark 5:1", "Mark 5:1"},
+	}
+
+	for _, c := range cases {
+		r, err := ref.ParseRange(c.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if out := mark4.Paragraph(*r); out.String() != c.out {
+			t.Errorf("Paragraph(%v) -> %v, wanted %v", c.in, out.String(), c.out)
+		}
+	}
+}
+
+func TestSliceFocus(t *testing.T) {
+	para, _ := ref.ParseRange("Mark 4:3-5")
+	want, _ := ref.ParseRange("Mark 4:4")
+
+	p := mark4.Slice(*para)
+	p.Focus(*want)
+
+	if p.Reference != "Mark 4:3-5" || len(p.Verses) != 3 {
+		t.Fatalf("sliced %v with %d verses, wanted Mark 4:3-5 with 3", p.Reference, len(p.Verses))
+	}
+
+	for _, v := range p.Verses {
+		if focus := v.Ref.Verse() == 4; v.Focus != focus {
+			t.Errorf("%v in focus: %v, wanted %v", v.Ref.String(), v.Focus, focus)
+		}
+	}
+
+	if mark4.Verses[3].Focus {
+		t.Errorf("focusing a slice changed the original passage")
+	}
+}
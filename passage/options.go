@@ -0,0 +1,16 @@
+package passage
+
+// Options controls what is included when a passage is rendered. Providers that
+// format passages themselves map these onto their own settings; otherwise they
+// are applied by the render package.
+type Options struct {
+	VerseNumbers bool `toml:"verse_numbers"`
+	Headings     bool `toml:"headings"`
+	Subheadings  bool `toml:"subheadings"`
+	Footnotes    bool `toml:"footnotes"`
+	References   bool `toml:"passage_references"`
+	Copyright    bool `toml:"copyright"`
+
+	// LineLength is the column to wrap text at, or 0 to leave lines unwrapped
+	LineLength int `toml:"line_length"`
+}
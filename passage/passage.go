@@ -0,0 +1,81 @@
+// Package passage models the text of a Bible passage independently of the
+// service it was fetched from.
+package passage
+
+import (
+	"github.com/dtjm/bible/ref"
+)
+
+// Passage is the text of a range of verses in a single translation
+type Passage struct {
+	Range ref.Range `json:"range"`
+
+	// Reference is the human-readable reference reported by the provider
+	Reference   string  `json:"reference"`
+	Translation string  `json:"translation"`
+	Verses      []Verse `json:"verses"`
+	Copyright   string  `json:"copyright,omitempty"`
+}
+
+// Verse is a single numbered verse of a passage
+type Verse struct {
+	Ref ref.Ref `json:"ref"`
+
+	// Text is the text of the verse. Poetry has one line of the poem per line
+	// of text; prose is a single line.
+	Text string `json:"text"`
+
+	// Headings are the section headings that come before the verse
+	Headings []Heading `json:"headings,omitempty"`
+
+	// Paragraph is true when the verse begins a new paragraph or stanza
+	Paragraph bool `json:"paragraph,omitempty"`
+
+	// Poetry is true when the verse is set as lines of poetry
+	Poetry bool `json:"poetry,omitempty"`
+
+	Footnotes []Footnote `json:"footnotes,omitempty"`
+
+	// WordsOfJesus marks the parts of Text spoken by Jesus, for printing in
+	// red letters
+	WordsOfJesus []Span `json:"words_of_jesus,omitempty"`
+
+	// Focus is true for the verses that were asked for when the passage has
+	// been widened to show their context
+	Focus bool `json:"focus,omitempty"`
+
+	// Highlight is the colour the user has highlighted the verse in, if any
+	Highlight string `json:"highlight,omitempty"`
+
+	// Notes are the user's notes on passages that end at this verse
+	Notes []Note `json:"notes,omitempty"`
+}
+
+// Heading is a section heading added by the translators
+type Heading struct {
+	// Level is 1 for a heading and 2 for a subheading
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+}
+
+// Footnote is a translator's note on a verse
+type Footnote struct {
+	// Offset is the position in the verse's Text, in bytes, that the note
+	// refers to
+	Offset int    `json:"offset"`
+	Text   string `json:"text"`
+}
+
+// Note is a note the user has made on a passage
+type Note struct {
+	ID   int    `json:"id"`
+	Ref  string `json:"ref"`
+	Text string `json:"text"`
+}
+
+// Span is a part of a verse's Text, from Start up to but not including End,
+// in bytes
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
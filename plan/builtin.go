@@ -0,0 +1,72 @@
+package plan
+
+// builtin is a plan laid out by splitting each of its streams into a portion
+// for each day. A day's reading is the day's portion from every stream.
+type builtin struct {
+	title   string
+	days    int
+	monthly bool
+	streams [][]string
+}
+
+var builtins = map[string]builtin{
+	// Robert Murray M'Cheyne's calendar, which reads the Old Testament once
+	// and the New Testament and Psalms twice in a year, in four streams: two
+	// for the family and two for private reading
+	"mcheyne": {
+		title: "M'Cheyne",
+		days:  365,
+		streams: [][]string{
+			{"Genesis-2 Chronicles"},
+			{"Matthew-Revelation"},
+			{"Ezra-Malachi"},
+			{"Acts-Revelation", "Psalms", "Matthew-John"},
+		},
+	},
+
+	"year": {
+		title:   "Bible in a Year",
+		days:    365,
+		streams: [][]string{{"Genesis-Revelation"}},
+	},
+
+	// The books and parts of books in the order the events happened, with
+	// the prophets alongside the kings they spoke to and the letters
+	// alongside Acts
+	"chronological": {
+		title: "Chronological",
+		days:  365,
+		streams: [][]string{{
+			"Genesis 1-11", "Job", "Genesis 12-50", "Exodus", "Leviticus",
+			"Numbers", "Deuteronomy", "Joshua", "Judges", "Ruth", "1 Samuel",
+			"2 Samuel", "1 Chronicles", "Psalms", "1 Kings 1-11",
+			"2 Chronicles 1-9", "Proverbs", "Ecclesiastes", "Song of Solomon",
+			"1 Kings 12-22", "2 Chronicles 10-20", "2 Kings 1-14", "Jonah",
+			"Amos", "Hosea", "Micah", "Isaiah", "2 Kings 15-25",
+			"2 Chronicles 21-36", "Joel", "Nahum", "Habakkuk", "Zephaniah",
+			"Obadiah", "Jeremiah", "Lamentations", "Ezekiel", "Daniel",
+			"Ezra 1-6", "Haggai", "Zechariah", "Esther", "Ezra 7-10",
+			"Nehemiah", "Malachi", "Matthew", "Mark", "Luke", "John",
+			"Acts 1-14", "James", "Galatians", "Acts 15-18", "1 Thessalonians",
+			"2 Thessalonians", "Acts 19-20", "1 Corinthians", "2 Corinthians",
+			"Romans", "Acts 21-28", "Philemon", "Colossians", "Ephesians",
+			"Philippians", "1 Timothy", "Titus", "1 Peter", "Hebrews",
+			"2 Timothy", "2 Peter", "Jude", "1 John", "2 John", "3 John",
+			"Revelation",
+		}},
+	},
+
+	"nt90": {
+		title:   "New Testament in 90 Days",
+		days:    90,
+		streams: [][]string{{"Matthew-Revelation"}},
+	},
+
+	// Psalms and a chapter of Proverbs for each day of the month
+	"psalms-proverbs": {
+		title:   "Psalms and Proverbs Monthly",
+		days:    31,
+		monthly: true,
+		streams: [][]string{{"Psalms"}, {"Proverbs"}},
+	},
+}
@@ -0,0 +1,163 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/dtjm/bible/ref"
+)
+
+// Formats lists the formats a plan can be written in
+var Formats = []string{"toml", "json"}
+
+// file is a plan as it is written out, with each day a list of references,
+// e.g.
+//
+//	name = "lent"
+//	title = "Paul over Lent"
+//	skip = ["sunday"]
+//	days = [["Romans 1-2"], ["Romans 3:1-4:12"]]
+type file struct {
+	Name    string     `toml:"name" json:"name"`
+	Title   string     `toml:"title" json:"title"`
+	Monthly bool       `toml:"monthly" json:"monthly,omitempty"`
+	Skip    []string   `toml:"skip" json:"skip,omitempty"`
+	Days    [][]string `toml:"days" json:"days"`
+}
+
+// FormatOf returns the format of a plan file from its extension
+func FormatOf(path string) (string, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	for _, f := range Formats {
+		if format == f {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unknown plan format %q, expected a file ending in one of %q", format, Formats)
+}
+
+// Write writes the plan in one of Formats
+func (p *Plan) Write(w io.Writer, format string) error {
+	f := file{Name: p.Name, Title: p.Title, Monthly: p.Monthly}
+	for _, wd := range p.Skip {
+		f.Skip = append(f.Skip, strings.ToLower(wd.String()))
+	}
+	for _, d := range p.Days {
+		var refs []string
+		for i := range d {
+			refs = append(refs, d[i].String())
+		}
+		f.Days = append(f.Days, refs)
+	}
+
+	switch format {
+	case "toml":
+		return toml.NewEncoder(w).Encode(f)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(f)
+	}
+
+	return fmt.Errorf("Unknown plan format %q, expected one of %q", format, Formats)
+}
+
+// Read reads a plan written by Write
+func Read(r io.Reader, format string) (*Plan, error) {
+	var f file
+	var err error
+	switch format {
+	case "toml":
+		_, err = toml.DecodeReader(r, &f)
+	case "json":
+		err = json.NewDecoder(r).Decode(&f)
+	default:
+		err = fmt.Errorf("Unknown plan format %q, expected one of %q", format, Formats)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading plan: %s", err)
+	}
+
+	if f.Name == "" || len(f.Days) == 0 {
+		return nil, fmt.Errorf("Error reading plan: it needs a name and at least one day")
+	}
+	p := &Plan{Name: strings.ToLower(f.Name), Title: f.Title, Monthly: f.Monthly}
+	if p.Title == "" {
+		p.Title = f.Name
+	}
+	if p.Skip, err = ParseWeekdays(strings.Join(f.Skip, ",")); err != nil {
+		return nil, fmt.Errorf("Error reading plan %s: %s", f.Name, err)
+	}
+	for i, refs := range f.Days {
+		var d Day
+		for _, s := range refs {
+			r, err := ref.ParseRange(s)
+			if err != nil {
+				return nil, fmt.Errorf("Error reading plan %s, day %d: %s", f.Name, i+1, err)
+			}
+			d = append(d, *r)
+		}
+		p.Days = append(p.Days, d)
+	}
+
+	return p, nil
+}
+
+// MarshalJSON encodes the plan as Write does in JSON
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	err := p.Write(&buf, "json")
+	return buf.Bytes(), err
+}
+
+// UnmarshalJSON decodes a plan written by MarshalJSON
+func (p *Plan) UnmarshalJSON(data []byte) error {
+	q, err := Read(bytes.NewReader(data), "json")
+	if err != nil {
+		return err
+	}
+
+	*p = *q
+	return nil
+}
+
+// ParseWeekdays parses a comma-separated list of weekdays, written out or
+// shortened to their first three letters, e.g. "sat,sun"
+func ParseWeekdays(s string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			full := strings.ToLower(wd.String())
+			if name == full || name == full[:3] {
+				days, found = append(days, wd), true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown weekday %q", name)
+		}
+	}
+
+	// A plan has to be read on some day, or it never advances
+	skipped := map[time.Weekday]bool{}
+	for _, wd := range days {
+		skipped[wd] = true
+	}
+	if len(skipped) == 7 {
+		return nil, fmt.Errorf("Can't skip every day of the week")
+	}
+
+	return days, nil
+}
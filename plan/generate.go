@@ -0,0 +1,136 @@
+package plan
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dtjm/bible/ref"
+)
+
+// Verse is a verse to be read in a generated plan
+type Verse struct {
+	Ref ref.Ref
+
+	// Weight is how long the verse takes to read: 1 to balance days by the
+	// number of verses, or the number of words in it
+	Weight int
+
+	// Section is true when a section, or pericope, starts at the verse
+	Section bool
+}
+
+// Costs of ending a day's reading before a verse that doesn't start a
+// chapter, counted as if the day were that many days' reading away from an
+// even share
+const (
+	sectionCost = 0.25
+	verseCost   = 1.0
+)
+
+// Verses returns the verses in ranges, in order, each weighing 1
+func Verses(ranges []ref.Range) []Verse {
+	var verses []Verse
+	for _, r := range ranges {
+		v := r.Verses()
+		for at := v.Start; ; {
+			verses = append(verses, Verse{Ref: at, Weight: 1})
+			if at == v.End {
+				break
+			}
+
+			next := at.Add(1)
+			if *next == at {
+				next = ref.New(at.Book().Next(), 1, 1)
+			}
+			at = *next
+		}
+	}
+
+	return verses
+}
+
+// Generate divides verses into days portions of about the same weight. Each
+// day ends where it comes closest to an even share of the whole, counting
+// ending in the middle of a chapter as further away, and in the middle of a
+// section further still, so that portions end at the ends of chapters and
+// sections where they can.
+func Generate(verses []Verse, days int) ([]Day, error) {
+	if days < 1 {
+		return nil, fmt.Errorf("A plan needs at least one day")
+	}
+	if len(verses) < days {
+		return nil, fmt.Errorf("Can't divide %d verses into %d days", len(verses), days)
+	}
+
+	// sum[i] is the weight of the verses before verses[i]
+	sum := make([]int, len(verses)+1)
+	for i, v := range verses {
+		sum[i+1] = sum[i] + v.Weight
+	}
+	perDay := float64(sum[len(verses)]) / float64(days)
+	if perDay == 0 {
+		perDay = 1
+	}
+
+	portions := make([]Day, days)
+	start := 0
+	for d := 1; d <= days; d++ {
+		end := len(verses)
+		if d < days {
+			// Leave at least a verse for each day to come
+			end = start + 1
+			target := perDay * float64(d)
+			best := math.Inf(1)
+			for i := start + 1; i <= len(verses)-(days-d); i++ {
+				off := float64(sum[i]) - target
+				if off > perDay {
+					break
+				}
+				if score := math.Abs(off)/perDay + breakCost(verses, i); score < best {
+					end, best = i, score
+				}
+			}
+		}
+
+		portions[d-1] = portion(verses[start:end])
+		start = end
+	}
+
+	return portions, nil
+}
+
+// breakCost returns the cost of ending a day's reading before verses[i]
+func breakCost(verses []Verse, i int) float64 {
+	v, prev := verses[i].Ref, verses[i-1].Ref
+	switch {
+	case v.Verse() == 1 || *prev.Add(1) != v:
+		// The start of a chapter, or of another range
+		return 0
+	case verses[i].Section:
+		return sectionCost
+	}
+
+	return verseCost
+}
+
+// portion turns verses into ranges, one for each run of verses that follow
+// on from each other in a book. Runs of whole chapters are written as such.
+func portion(verses []Verse) Day {
+	var d Day
+	for i, v := range verses {
+		if i > 0 && v.Ref.Book() == verses[i-1].Ref.Book() && *verses[i-1].Ref.Add(1) == v.Ref {
+			d[len(d)-1].End = v.Ref
+			continue
+		}
+		d = append(d, ref.Range{Start: v.Ref, End: v.Ref})
+	}
+
+	for i, r := range d {
+		s, e := r.Start, r.End
+		if s.Verse() == 1 && e.Verse() == e.Book().Verses(e.Chapter()) {
+			d[i] = ref.Range{Start: *ref.New(s.Book(), s.Chapter(), 0), End: *ref.New(e.Book(), e.Chapter(), 0)}
+		}
+	}
+
+	return d
+}
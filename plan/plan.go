@@ -0,0 +1,138 @@
+// Package plan lays out reading plans, which divide parts of the Bible into
+// a reading for each day, and keeps track of how far through them a reader
+// is.
+package plan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dtjm/bible/ref"
+)
+
+// Plan is a reading for each day
+type Plan struct {
+	Name  string
+	Title string
+
+	// Monthly plans read the day of the month each day, starting again
+	// each month, and never end
+	Monthly bool
+
+	// Skip lists weekdays with no reading
+	Skip []time.Weekday
+
+	Days []Day
+}
+
+// ReadsOn reports whether there is a reading on the date t falls on, or
+// whether its weekday is skipped
+func (p *Plan) ReadsOn(t time.Time) bool {
+	for _, w := range p.Skip {
+		if t.Weekday() == w {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Day is what is read on one day of a plan
+type Day []ref.Range
+
+func (d Day) String() string {
+	refs := make([]string, len(d))
+	for i := range d {
+		refs[i] = d[i].String()
+	}
+
+	return strings.Join(refs, "; ")
+}
+
+// Names returns the names of the built-in plans
+func Names() []string {
+	var names []string
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Get returns the built-in plan called name
+func Get(name string) (*Plan, error) {
+	b, ok := builtins[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("Unknown plan %q, expected one of %q", name, Names())
+	}
+
+	p := &Plan{Name: strings.ToLower(name), Title: b.title, Monthly: b.monthly, Days: make([]Day, b.days)}
+	for _, stream := range b.streams {
+		var ranges []ref.Range
+		for _, s := range stream {
+			r, err := ref.ParseRange(s)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing plan %s: %s", name, err)
+			}
+			ranges = append(ranges, *r)
+		}
+
+		for i, portion := range Split(ranges, b.days) {
+			p.Days[i] = append(p.Days[i], portion...)
+		}
+	}
+
+	return p, nil
+}
+
+// Split divides ranges into days portions of whole chapters, with as near
+// the same number of chapters in each as can be. A portion is a range for
+// each run of chapters in a book. With fewer chapters than days, some days
+// towards the end get none.
+func Split(ranges []ref.Range, days int) []Day {
+	var chapters []ref.Ref
+	for _, r := range ranges {
+		chapters = append(chapters, Chapters(r)...)
+	}
+
+	// Rounding up puts any longer portions first and a chapter on the first
+	// day
+	n := len(chapters)
+	at := func(d int) int { return (d*n + days - 1) / days }
+	portions := make([]Day, days)
+	for d := range portions {
+		portions[d] = join(chapters[at(d):at(d+1)])
+	}
+
+	return portions
+}
+
+// Chapters returns the chapters a range touches, in order
+func Chapters(r ref.Range) []ref.Ref {
+	v := r.Verses()
+	var chapters []ref.Ref
+	for c := ref.New(v.Start.Book(), v.Start.Chapter(), 0); ; c = c.NextChapter() {
+		chapters = append(chapters, *c)
+		if c.Book() == v.End.Book() && c.Chapter() == v.End.Chapter() {
+			return chapters
+		}
+	}
+}
+
+// join turns chapters into ranges, one for each run of chapters that follow
+// on from each other in the same book
+func join(chapters []ref.Ref) Day {
+	var d Day
+	for i, c := range chapters {
+		if i > 0 && *chapters[i-1].NextChapter() == c && chapters[i-1].Book() == c.Book() {
+			d[len(d)-1].End = c
+			continue
+		}
+		d = append(d, ref.Range{Start: c, End: c})
+	}
+
+	return d
+}
@@ -0,0 +1,290 @@
+package plan
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtjm/bible/ref"
+)
+
+func TestBuiltins(t *testing.T) {
+	cases := []struct {
+		name        string
+		days        int
+		first, last string
+		chapters    int
+	}{
+		{"mcheyne", 365, "Genesis 1-2; Matthew 1; Ezra 1-2; Acts 1-2", "2 Chronicles 36; Malachi 4; John 21", 403 + 260 + 526 + 410},
+		{"year", 365, "Genesis 1-4", "Revelation 20-22", 1189},
+		{"chronological", 365, "Genesis 1-4", "Revelation 20-22", 1189},
+		{"nt90", 90, "Matthew 1-3", "Revelation 21-22", 260},
+		{"psalms-proverbs", 31, "Psalm 1-5; Proverbs 1", "Psalm 147-150; Proverbs 31", 181},
+	}
+
+	for _, c := range cases {
+		p, err := Get(c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(p.Days) != c.days {
+			t.Errorf("%s has %d days, wanted %d", c.name, len(p.Days), c.days)
+			continue
+		}
+		if first := p.Days[0].String(); first != c.first {
+			t.Errorf("%s day 1 -> %v, wanted %v", c.name, first, c.first)
+		}
+		if last := p.Days[c.days-1].String(); last != c.last {
+			t.Errorf("%s last day -> %v, wanted %v", c.name, last, c.last)
+		}
+
+		chapters := 0
+		for i, d := range p.Days {
+			if len(d) == 0 {
+				t.Errorf("%s day %d is empty", c.name, i+1)
+			}
+			for _, r := range d {
+				chapters += len(Chapters(r))
+			}
+		}
+		if chapters != c.chapters {
+			t.Errorf("%s reads %d chapters, wanted %d", c.name, chapters, c.chapters)
+		}
+	}
+
+	if _, err := Get("nonesuch"); err == nil {
+		t.Errorf("expected an error for an unknown plan")
+	}
+}
+
+func TestChronologicalCoversBible(t *testing.T) {
+	p, err := Get("chronological")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[ref.Ref]int)
+	for _, d := range p.Days {
+		for _, r := range d {
+			for _, c := range Chapters(r) {
+				seen[c]++
+			}
+		}
+	}
+
+	for b := ref.Genesis; b <= ref.Revelation; b++ {
+		for c := 1; c <= b.Chapters(); c++ {
+			if n := seen[*ref.New(b, c, 0)]; n != 1 {
+				t.Errorf("%s %d is read %d times", b, c, n)
+			}
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	p := &Plan{Days: make([]Day, 10)}
+	pr := &Progress{Start: Date(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))}
+	now := time.Date(2026, 10, 5, 21, 30, 0, 0, time.UTC)
+
+	if day := pr.Today(p, now); day != 5 {
+		t.Errorf("Today -> %d, wanted 5", day)
+	}
+	if day := pr.Today(p, now.AddDate(0, 0, -5)); day != 0 {
+		t.Errorf("Today before the start -> %d", day)
+	}
+	if day := pr.Today(p, now.AddDate(0, 0, 10)); day != 0 {
+		t.Errorf("Today after the end -> %d", day)
+	}
+
+	pr.MarkDone(2, true)
+	pr.MarkDone(1, true)
+	pr.MarkDone(4, true)
+	pr.MarkDone(4, false)
+	if !reflect.DeepEqual(pr.Done, []int{1, 2}) {
+		t.Errorf("Done -> %v", pr.Done)
+	}
+	if missed := pr.Missed(p, now); !reflect.DeepEqual(missed, []int{3, 4}) {
+		t.Errorf("Missed -> %v, wanted [3 4]", missed)
+	}
+
+	if day := pr.Reschedule(p, now); day != 3 {
+		t.Errorf("Reschedule -> %d, wanted 3", day)
+	}
+	if day := pr.Today(p, now); day != 3 {
+		t.Errorf("Today after rescheduling -> %d, wanted 3", day)
+	}
+	if missed := pr.Missed(p, now); len(missed) != 0 {
+		t.Errorf("Missed after rescheduling -> %v", missed)
+	}
+}
+
+func TestMonthly(t *testing.T) {
+	p, err := Get("psalms-proverbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	pr := &Progress{Start: start}
+
+	cases := []struct {
+		date, read string
+	}{
+		{"2026-10-19", "Psalm 89-92; Proverbs 19"},
+		{"2026-10-31", "Psalm 147-150; Proverbs 31"},
+		{"2026-11-29", "Psalm 137-141; Proverbs 29"},
+		{"2026-11-30", "Psalm 142-146; Proverbs 30; Psalm 147-150; Proverbs 31"},
+		{"2027-02-01", "Psalm 1-5; Proverbs 1"},
+	}
+
+	for _, c := range cases {
+		now, _ := time.Parse(DateFormat, c.date)
+		day := pr.Today(p, now)
+		if day < 1 {
+			t.Errorf("Today(%s) -> %d", c.date, day)
+			continue
+		}
+		if got := pr.Reading(p, day).String(); got != c.read {
+			t.Errorf("Reading(%s) -> %v, wanted %v", c.date, got, c.read)
+		}
+	}
+
+	pr.MarkDone(1, true)
+	now := time.Date(2026, 11, 3, 12, 0, 0, 0, time.UTC)
+	if missed := pr.Missed(p, now); len(missed) != 2 || pr.Date(p, missed[0]).Day() != 1 {
+		t.Errorf("Missed in November -> %v", missed)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	cases := []struct {
+		ranges   string
+		days     int
+		sections []string
+		want     []string
+	}{
+		{"Romans", 8, nil, []string{"Romans 1-2", "Romans 3-4", "Romans 5-6", "Romans 7-8", "Romans 9-10", "Romans 11", "Romans 12-14", "Romans 15-16"}},
+		{"Psalm 119", 4, nil, []string{"Psalm 119:1-44", "Psalm 119:45-88", "Psalm 119:89-132", "Psalm 119:133-176"}},
+		{"Jude", 3, nil, []string{"Jude 1:1-8", "Jude 1:9-17", "Jude 1:18-25"}},
+		{"Jude", 3, []string{"Jude 1:5", "Jude 1:17", "Jude 1:24"}, []string{"Jude 1:1-4", "Jude 1:5-16", "Jude 1:17-25"}},
+		{"Ruth 4; Jonah", 2, nil, []string{"Ruth 4; Jonah 1", "Jonah 2-4"}},
+	}
+
+	for _, c := range cases {
+		var ranges []ref.Range
+		for _, s := range strings.Split(c.ranges, ";") {
+			r, err := ref.ParseRange(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ranges = append(ranges, *r)
+		}
+
+		verses := Verses(ranges)
+		for i := range verses {
+			for _, s := range c.sections {
+				if verses[i].Ref.String() == s {
+					verses[i].Section = true
+				}
+			}
+		}
+
+		days, err := Generate(verses, c.days)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, d := range days {
+			got = append(got, d.String())
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Generate(%s, %d) -> %q, wanted %q", c.ranges, c.days, got, c.want)
+		}
+	}
+
+	jude, _ := ref.ParseRange("Jude")
+	if _, err := Generate(Verses([]ref.Range{*jude}), 30); err == nil {
+		t.Errorf("expected an error for more days than verses")
+	}
+}
+
+func TestFile(t *testing.T) {
+	romans, _ := ref.ParseRange("Romans")
+	days, err := Generate(Verses([]ref.Range{*romans}), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Plan{Name: "romans", Title: "Romans in a week", Skip: []time.Weekday{time.Sunday}, Days: days}
+
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		if err := p.Write(&buf, format); err != nil {
+			t.Fatal(err)
+		}
+		read, err := Read(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if !reflect.DeepEqual(read, p) {
+			t.Errorf("%s: read back %+v, wanted %+v", format, read, p)
+		}
+	}
+
+	if _, err := Read(strings.NewReader(`{"name": "x", "days": [["not a reference"]]}`), "json"); err == nil {
+		t.Errorf("expected an error for a bad reference")
+	}
+	if _, err := FormatOf("plan.yaml"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestSkip(t *testing.T) {
+	p := &Plan{Skip: []time.Weekday{time.Saturday, time.Sunday}, Days: make([]Day, 10)}
+	// A Friday
+	pr := &Progress{Start: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)}
+
+	cases := []struct {
+		date  string
+		today int
+	}{
+		{"2026-10-16", 1},
+		{"2026-10-17", 0},
+		{"2026-10-18", 0},
+		{"2026-10-19", 2},
+		{"2026-10-29", 10},
+		{"2026-10-30", 0},
+	}
+	for _, c := range cases {
+		now, _ := time.Parse(DateFormat, c.date)
+		if day := pr.Today(p, now); day != c.today {
+			t.Errorf("Today(%s) -> %d, wanted %d", c.date, day, c.today)
+		}
+	}
+
+	if date := pr.Date(p, 2).Format(DateFormat); date != "2026-10-19" {
+		t.Errorf("Date(2) -> %s", date)
+	}
+
+	sunday := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	if missed := pr.Missed(p, sunday); !reflect.DeepEqual(missed, []int{1}) {
+		t.Errorf("Missed on Sunday -> %v", missed)
+	}
+	if day := pr.Reschedule(p, sunday); day != 1 || pr.Start.Format(DateFormat) != "2026-10-19" {
+		t.Errorf("Reschedule on Sunday -> day %d from %s", day, pr.Start.Format(DateFormat))
+	}
+
+	if days, err := ParseWeekdays("Sat, sunday"); err != nil || !reflect.DeepEqual(days, p.Skip) {
+		t.Errorf("ParseWeekdays -> %v, %v", days, err)
+	}
+	if _, err := ParseWeekdays("someday"); err == nil {
+		t.Errorf("expected an error for an unknown weekday")
+	}
+	if _, err := ParseWeekdays("mon,tue,wed,thu,fri,sat,sun"); err == nil {
+		t.Errorf("expected an error for skipping every day")
+	}
+	if _, err := Read(strings.NewReader(`{"name": "never", "skip": ["mon", "tue", "wed", "thu", "fri", "saturday", "sunday"], "days": [["John 1"]]}`), "json"); err == nil {
+		t.Errorf("expected an error reading a plan that skips every day")
+	}
+}
@@ -0,0 +1,167 @@
+package plan
+
+import (
+	"sort"
+	"time"
+)
+
+// DateFormat is the layout of the dates plans are started and shown on
+const DateFormat = "2006-01-02"
+
+// Progress is how far a reader has got through a plan
+type Progress struct {
+	// Start is the date of the first day, at midnight UTC
+	Start time.Time `toml:"start" json:"start"`
+
+	// Done lists the days that have been read, counting from 1
+	Done []int `toml:"done" json:"done"`
+}
+
+// Date returns the date t falls on, as a time at midnight UTC
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Day returns the number of reading days from the start of p up to and
+// including now, which is the day of the plan that falls on now if p is read
+// on now. It is 0 or less before the plan starts.
+func (pr *Progress) Day(p *Plan, now time.Time) int {
+	days := int(Date(now).Sub(pr.Start).Hours()/24) + 1
+	if days < 1 || len(p.Skip) == 0 {
+		return days
+	}
+
+	day := 0
+	for d := pr.Start; !d.After(Date(now)); d = d.AddDate(0, 0, 1) {
+		if p.ReadsOn(d) {
+			day++
+		}
+	}
+	return day
+}
+
+// Date returns the date day of p falls on
+func (pr *Progress) Date(p *Plan, day int) time.Time {
+	d := pr.Start
+	for !p.ReadsOn(d) {
+		d = d.AddDate(0, 0, 1)
+	}
+	for ; day > 1; day-- {
+		d = d.AddDate(0, 0, 1)
+		for !p.ReadsOn(d) {
+			d = d.AddDate(0, 0, 1)
+		}
+	}
+
+	return d
+}
+
+// Today returns the day of p that falls on now, or 0 before the plan starts,
+// after it ends, or on a weekday it skips. Monthly plans never end.
+func (pr *Progress) Today(p *Plan, now time.Time) int {
+	day := pr.Day(p, now)
+	if day < 1 || !p.ReadsOn(now) || !p.Monthly && day > len(p.Days) {
+		return 0
+	}
+
+	return day
+}
+
+// Reading returns what is read on day. A monthly plan reads the day of the
+// month, and on the last day of the month, the days left that the month
+// doesn't have.
+func (pr *Progress) Reading(p *Plan, day int) Day {
+	if !p.Monthly {
+		return p.Days[day-1]
+	}
+
+	date := pr.Date(p, day)
+	last := date.Day()
+	if date.AddDate(0, 0, 1).Day() == 1 {
+		last = len(p.Days)
+	}
+
+	var d Day
+	for i := date.Day(); i <= last && i <= len(p.Days); i++ {
+		d = append(d, p.Days[i-1]...)
+	}
+	return d
+}
+
+// IsDone reports whether day has been read
+func (pr *Progress) IsDone(day int) bool {
+	for _, d := range pr.Done {
+		if d == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarkDone records day as read, or as not read if done is false
+func (pr *Progress) MarkDone(day int, done bool) {
+	var kept []int
+	for _, d := range pr.Done {
+		if d != day {
+			kept = append(kept, d)
+		}
+	}
+	if done {
+		kept = append(kept, day)
+	}
+	sort.Ints(kept)
+	pr.Done = kept
+}
+
+// Missed returns the days before now that haven't been read. Monthly plans
+// only count this month's.
+func (pr *Progress) Missed(p *Plan, now time.Time) []int {
+	past := pr.Day(p, now)
+	if p.ReadsOn(now) {
+		past--
+	}
+	if !p.Monthly && past > len(p.Days) {
+		past = len(p.Days)
+	}
+
+	first := 1
+	if p.Monthly {
+		first = past - now.Day() + 2
+	}
+
+	var missed []int
+	for d := first; d <= past; d++ {
+		if d >= 1 && !pr.IsDone(d) {
+			missed = append(missed, d)
+		}
+	}
+
+	return missed
+}
+
+// Reschedule moves the start of the plan on so that the first day not yet
+// read falls on now, putting off everything after it by as many days as
+// were missed. It returns that day. Monthly plans can't be put off, since
+// they follow the days of the month.
+func (pr *Progress) Reschedule(p *Plan, now time.Time) int {
+	first := 1
+	for pr.IsDone(first) && first < len(p.Days) {
+		first++
+	}
+
+	// Count back from the next day p is read on
+	start := Date(now)
+	for !p.ReadsOn(start) {
+		start = start.AddDate(0, 0, 1)
+	}
+	for day := 1; day < first; {
+		start = start.AddDate(0, 0, -1)
+		if p.ReadsOn(start) {
+			day++
+		}
+	}
+
+	pr.Start = start
+	return first
+}
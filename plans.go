@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/plan"
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/settings"
+)
+
+// planReadings returns the readings a plan: reference stands for: "today"
+// is today's from every plan that has been started, "catchup" is the days
+// that have been missed, and the name of a plan is today's from that plan
+func (c *config) planReadings(which string, now time.Time) ([]*reading, error) {
+	name := which
+	if which == "today" || which == "catchup" {
+		name = ""
+	}
+	names, err := c.startedPlans(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var readings []*reading
+	for _, name := range names {
+		p, err := loadPlan(name)
+		if err != nil {
+			return nil, err
+		}
+		pr := c.Plans[name]
+
+		var days []int
+		if day := pr.Today(p, now); day > 0 {
+			days = []int{day}
+		}
+		if which == "catchup" {
+			days = pr.Missed(p, now)
+		}
+		for _, day := range days {
+			readings = append(readings, dayReadings(p, &pr, day)...)
+		}
+	}
+
+	return readings, nil
+}
+
+// dayReadings returns the readings for a day of a plan
+func dayReadings(p *plan.Plan, pr *plan.Progress, day int) []*reading {
+	var readings []*reading
+	d := pr.Reading(p, day)
+	for i, r := range d {
+		readings = append(readings, &reading{
+			read:      r.String(),
+			plan:      p.Name,
+			day:       day,
+			dayName:   dayName(p, pr, day),
+			lastOfDay: i == len(d)-1,
+		})
+	}
+
+	return readings
+}
+
+// dayName names a day of a plan: its number, or for a monthly plan, which
+// follows the days of the month, its date
+func dayName(p *plan.Plan, pr *plan.Progress, day int) string {
+	if p.Monthly {
+		return pr.Date(p, day).Format(plan.DateFormat)
+	}
+
+	return fmt.Sprintf("day %d", day)
+}
+
+// startedPlans returns the names of the plans that have been started, or
+// just name if it is given and has been
+func (c *config) startedPlans(name string) ([]string, error) {
+	if name != "" {
+		if _, err := loadPlan(name); err != nil {
+			return nil, err
+		}
+		name = strings.ToLower(name)
+		if _, ok := c.Plans[name]; !ok {
+			return nil, fmt.Errorf("You haven't started the %s plan; start it with bible plan start %s", name, name)
+		}
+		return []string{name}, nil
+	}
+
+	var names []string
+	for _, name := range planNames() {
+		if _, ok := c.Plans[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("You haven't started a plan; see them with bible plan")
+	}
+
+	return names, nil
+}
+
+// formatDay writes a day's readings in the configured style
+func (c *config) formatDay(d plan.Day) string {
+	refs := make([]string, len(d))
+	for i := range d {
+		refs[i] = d[i].Format(c.RefStyle)
+	}
+
+	return strings.Join(refs, "; ")
+}
+
+// printPlans lists the built-in plans and how far through each one that has
+// been started the reader is
+func (c *config) printPlans(w io.Writer, now time.Time) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	for _, name := range planNames() {
+		p, err := loadPlan(name)
+		if err != nil {
+			return err
+		}
+
+		progress := ""
+		if pr, ok := c.Plans[name]; ok {
+			progress = fmt.Sprintf("%d done", len(pr.Done))
+			if day := pr.Today(p, now); day > 0 && !p.Monthly {
+				progress = fmt.Sprintf("day %d, %s", day, progress)
+			}
+			if missed := len(pr.Missed(p, now)); missed > 0 {
+				progress += fmt.Sprintf(", %d behind", missed)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d days\t%s\n", name, p.Title, len(p.Days), progress)
+	}
+	tw.Flush()
+
+	return writeColumns(w, buf.String())
+}
+
+// printDays lists days of a plan with their readings, saying which are done
+func (c *config) printDays(w io.Writer, p *plan.Plan, days []int) {
+	pr := c.Plans[p.Name]
+	for _, day := range days {
+		done := ""
+		if pr.IsDone(day) {
+			done = " (done)"
+		}
+		fmt.Fprintf(w, "%s %s: %s%s\n", p.Name, dayName(p, &pr, day), c.formatDay(pr.Reading(p, day)), done)
+	}
+}
+
+// plansDir returns the directory plans made with plan generate are kept in
+func plansDir() string {
+	return filepath.Join(settings.Dir(), "plans")
+}
+
+// planNames returns the names of the built-in plans and those that have been
+// generated, in order
+func planNames() []string {
+	names := plan.Names()
+	files, _ := filepath.Glob(filepath.Join(plansDir(), "*.toml"))
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(f), ".toml"))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// loadPlan returns the built-in or generated plan called name
+func loadPlan(name string) (*plan.Plan, error) {
+	if p, err := plan.Get(name); err == nil {
+		return p, nil
+	}
+
+	f, err := os.Open(filepath.Join(plansDir(), strings.ToLower(name)+".toml"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("Unknown plan %q, expected one of %q", name, planNames())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return plan.Read(f, "toml")
+}
+
+// savePlan keeps a generated plan in plansDir, where loadPlan finds it. It
+// can't replace a built-in plan, or one that has been started.
+func (c *config) savePlan(p *plan.Plan) error {
+	if _, err := plan.Get(p.Name); err == nil {
+		return fmt.Errorf("%s is the name of a built-in plan; choose another name", p.Name)
+	}
+	if _, ok := c.Plans[p.Name]; ok {
+		return fmt.Errorf("You have started the %s plan; stop it or choose another name", p.Name)
+	}
+
+	var buf bytes.Buffer
+	if err := p.Write(&buf, "toml"); err != nil {
+		return err
+	}
+
+	return settings.WriteData(filepath.Join(plansDir(), p.Name+".toml"), buf.Bytes())
+}
+
+// readPlanFile reads a plan from a TOML or JSON file
+func readPlanFile(path string) (*plan.Plan, error) {
+	format, err := plan.FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return plan.Read(f, format)
+}
+
+// writePlanFile writes a plan to a TOML or JSON file
+func writePlanFile(path string, p *plan.Plan) error {
+	format, err := plan.FormatOf(path)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := p.Write(&buf, format); err != nil {
+		return err
+	}
+
+	return settings.WriteData(path, buf.Bytes())
+}
+
+// parsePlanRanges parses what a plan is to be generated from: references or
+// groups of books such as "pauline", separated by commas or semicolons
+func parsePlanRanges(s string) ([]ref.Range, error) {
+	var ranges []ref.Range
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		part = strings.TrimSpace(part)
+		if books, err := ref.Group(part); err == nil {
+			for _, b := range books {
+				whole := ref.New(b, 0, 0)
+				ranges = append(ranges, ref.Range{Start: *whole, End: *whole})
+			}
+			continue
+		}
+
+		r, err := ref.ParseRange(part)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, *r)
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("Give the passages to make a plan from, e.g. Isaiah-Malachi")
+	}
+
+	return ranges, nil
+}
+
+// planVerses returns the verses in ranges, weighed by verses or by words.
+// Unless offline, the text is fetched a chapter at a time to find where
+// sections start and to count the words.
+func (c *config) planVerses(ranges []ref.Range, by, translation string, offline bool, jobs int) ([]plan.Verse, error) {
+	verses := plan.Verses(ranges)
+	switch {
+	case by != "verses" && by != "words":
+		return nil, fmt.Errorf("Unknown measure %q, expected verses or words", by)
+	case offline && by == "words":
+		return nil, fmt.Errorf("Words can't be counted without fetching the text")
+	case offline:
+		return verses, nil
+	}
+
+	var entries []*batchEntry
+	for i, v := range verses {
+		if i == 0 || v.Ref.Chapter() != verses[i-1].Ref.Chapter() || v.Ref.Book() != verses[i-1].Ref.Book() {
+			chapter := ref.New(v.Ref.Book(), v.Ref.Chapter(), 0)
+			entries = append(entries, &batchEntry{Input: chapter.String(), done: make(chan struct{})})
+		}
+	}
+
+	opts := passage.Options{Headings: true}
+	fetchBatch(entries, jobs, func(refString string) ([]*passage.Passage, error) {
+		return c.fetchPassages([]string{translation}, refString, opts)
+	})
+
+	text := make(map[ref.Ref]passage.Verse)
+	for _, e := range entries {
+		<-e.done
+		if e.err != nil {
+			return nil, e.err
+		}
+		for _, p := range e.Passages {
+			for _, v := range p.Verses {
+				text[v.Ref] = v
+			}
+		}
+	}
+
+	for i := range verses {
+		v := text[verses[i].Ref]
+		verses[i].Section = len(v.Headings) > 0
+		if by == "words" {
+			// Verses the translation leaves out weigh nothing
+			verses[i].Weight = len(strings.Fields(v.Text))
+		}
+	}
+
+	return verses, nil
+}
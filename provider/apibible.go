@@ -0,0 +1,306 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+const apiBibleBaseURL = "https://api.scripture.api.bible/v1"
+
+var apiBibleIDRegex = regexp.MustCompile("^[0-9a-f]{16}-[0-9a-f]{2}$")
+
+// APIBible fetches any of the translations licensed to an API.Bible key
+type APIBible struct {
+	key     string
+	baseURL string
+	client  *http.Client
+}
+
+// NewAPIBible returns an API.Bible provider. The key is required; sign up at
+// https://scripture.api.bible to get one.
+func NewAPIBible(c Config) *APIBible {
+	a := APIBible{key: c.Key, baseURL: c.BaseURL, client: http.DefaultClient}
+	if a.baseURL == "" {
+		a.baseURL = apiBibleBaseURL
+	}
+
+	return &a
+}
+
+// Name returns "apibible"
+func (a *APIBible) Name() string {
+	return "apibible"
+}
+
+// Passage fetches a passage. The translation may be a Bible ID such as
+// "de4e12af7f28f599-02" or an abbreviation such as "KJV", which is looked up
+// with Translations.
+//
+// The passage is fetched as structured JSON, so titles and notes are asked for
+// according to the options and everything else is left to the renderer.
+func (a *APIBible) Passage(q Query) (*passage.Passage, error) {
+	id, err := a.bibleID(q.Translation)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"content-type":            {"json"},
+		"include-notes":           {strconv.FormatBool(q.Options.Footnotes)},
+		"include-titles":          {strconv.FormatBool(q.Options.Headings || q.Options.Subheadings)},
+		"include-chapter-numbers": {"false"},
+		"include-verse-numbers":   {"true"},
+		"include-verse-spans":     {"false"},
+	}
+
+	var resp struct {
+		Data struct {
+			Reference string         `json:"reference"`
+			Content   []apiBibleNode `json:"content"`
+			Copyright string         `json:"copyright"`
+		} `json:"data"`
+	}
+
+	u := fmt.Sprintf("%s/bibles/%s/passages/%s?%s",
+		a.baseURL, id, apiBiblePassageID(q.Range), query.Encode())
+	if err := a.get(u, &resp); err != nil {
+		return nil, err
+	}
+
+	translation := q.Translation
+	if apiBibleIDRegex.MatchString(translation) {
+		translation = ""
+	}
+
+	b := newVerseBuilder(q.Range.Start)
+	for _, n := range resp.Data.Content {
+		n.walkPara(b)
+	}
+
+	return &passage.Passage{
+		Range:       q.Range,
+		Reference:   resp.Data.Reference,
+		Translation: strings.ToUpper(translation),
+		Verses:      b.done(),
+		Copyright:   cleanText(resp.Data.Copyright),
+	}, nil
+}
+
+// apiBibleNode is an element of a passage's JSON content, which mirrors the
+// USX markup of the underlying text
+type apiBibleNode struct {
+	Name  string                 `json:"name"`
+	Type  string                 `json:"type"`
+	Text  string                 `json:"text"`
+	Attrs map[string]interface{} `json:"attrs"`
+	Items []apiBibleNode         `json:"items"`
+}
+
+func (n *apiBibleNode) attr(name string) string {
+	s, _ := n.Attrs[name].(string)
+	return s
+}
+
+// walkPara adds a top-level paragraph to the passage. The paragraph style says
+// whether it is a heading, prose or a line of poetry.
+func (n *apiBibleNode) walkPara(b *verseBuilder) {
+	style := n.attr("style")
+	switch {
+	case style == "s" || style == "s1" || style == "ms" || style == "ms1":
+		b.heading(1, n.plainText())
+	case style == "s2" || style == "s3" || style == "ms2" || style == "sp":
+		b.heading(2, n.plainText())
+	case style == "r" || style == "mr" || style == "sr":
+		// Parallel passage references aren't part of the text
+	case style == "b":
+		b.breakParagraph()
+	case strings.HasPrefix(style, "q"):
+		b.breakLine()
+		n.walkItems(b, true)
+	default:
+		b.breakParagraph()
+		n.walkItems(b, false)
+	}
+}
+
+func (n *apiBibleNode) walkItems(b *verseBuilder, poetry bool) {
+	for _, item := range n.Items {
+		switch {
+		case item.Type == "text":
+			b.text(item.Text)
+		case item.Name == "verse":
+			chapter := 0
+			if sid := item.attr("sid"); strings.Contains(sid, ":") {
+				chapter = atoi(sid[strings.LastIndex(sid, " ")+1:])
+			}
+			b.verse(chapter, atoi(item.attr("number")), poetry)
+		case item.Name == "note":
+			b.footnote(item.noteText())
+		case item.attr("style") == "wj":
+			b.startWordsOfJesus()
+			item.walkItems(b, poetry)
+			b.endWordsOfJesus()
+		default:
+			item.walkItems(b, poetry)
+		}
+	}
+}
+
+// plainText returns all the text inside the node, leaving out notes
+func (n *apiBibleNode) plainText() string {
+	if n.Type == "text" {
+		return n.Text
+	}
+
+	var texts []string
+	for _, item := range n.Items {
+		if item.Name != "note" {
+			texts = append(texts, item.plainText())
+		}
+	}
+
+	return strings.Join(texts, "")
+}
+
+// noteText returns the text of a footnote, leaving out the reference to the
+// verse it is attached to, which is marked with the "fr" style
+func (n *apiBibleNode) noteText() string {
+	var texts []string
+	for _, item := range n.Items {
+		if item.attr("style") != "fr" {
+			texts = append(texts, item.plainText())
+		}
+	}
+
+	return strings.Join(texts, "")
+}
+
+// Translations lists the Bibles the key has access to
+func (a *APIBible) Translations() ([]Translation, error) {
+	var resp struct {
+		Data []struct {
+			ID                string `json:"id"`
+			Abbreviation      string `json:"abbreviation"`
+			AbbreviationLocal string `json:"abbreviationLocal"`
+			Name              string `json:"name"`
+			Language          struct {
+				Name string `json:"name"`
+			} `json:"language"`
+		} `json:"data"`
+	}
+
+	if err := a.get(a.baseURL+"/bibles", &resp); err != nil {
+		return nil, err
+	}
+
+	translations := make([]Translation, len(resp.Data))
+	for i, b := range resp.Data {
+		abbr := b.AbbreviationLocal
+		if abbr == "" {
+			abbr = b.Abbreviation
+		}
+
+		translations[i] = Translation{
+			ID:           b.ID,
+			Abbreviation: abbr,
+			Name:         b.Name,
+			Language:     b.Language.Name,
+		}
+	}
+
+	return translations, nil
+}
+
+// bibleID resolves a translation abbreviation to a Bible ID
+func (a *APIBible) bibleID(translation string) (string, error) {
+	if apiBibleIDRegex.MatchString(translation) {
+		return translation, nil
+	}
+
+	translations, err := a.Translations()
+	if err != nil {
+		return "", err
+	}
+
+	for _, t := range translations {
+		if strings.EqualFold(t.Abbreviation, translation) {
+			return t.ID, nil
+		}
+	}
+
+	return "", &Error{
+		Provider: a.Name(),
+		Message:  fmt.Sprintf("no Bible with abbreviation %q", translation),
+		Err:      ErrNotFound,
+	}
+}
+
+// get decodes the JSON response from u into v. Errors come back as
+// {"statusCode": 401, "error": "Unauthorized", "message": "..."}.
+func (a *APIBible) get(u string, v interface{}) error {
+	if a.key == "" {
+		return &Error{
+			Provider: a.Name(),
+			Message:  "set key in the [providers.apibible] section of the config file",
+			Err:      ErrUnauthorized,
+		}
+	}
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("api-key", a.key)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return statusError(a.Name(), resp.StatusCode, e.Message)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// apiBiblePassageID converts a range into a passage ID such as "JHN.3.16" or
+// "JHN.3.16-JHN.4.2"
+func apiBiblePassageID(r ref.Range) string {
+	start, end := r.Start, r.End
+	if start.Chapter() == 0 {
+		start = *ref.New(start.Book(), 1, 0)
+		end = *ref.New(end.Book(), end.Book().Chapters(), 0)
+	}
+
+	if start == end {
+		return apiBibleRefID(start)
+	}
+
+	return apiBibleRefID(start) + "-" + apiBibleRefID(end)
+}
+
+func apiBibleRefID(r ref.Ref) string {
+	id := r.Book().USFM()
+	if r.Chapter() > 0 {
+		id += fmt.Sprintf(".%d", r.Chapter())
+	}
+	if r.Verse() > 0 {
+		id += fmt.Sprintf(".%d", r.Verse())
+	}
+
+	return id
+}
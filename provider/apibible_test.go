@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dtjm/bible/ref"
+)
+
+func TestAPIBiblePassage(t *testing.T) {
+	ts := fixtureServer(t, map[string]string{
+		"/bibles": "apibible_bibles.json",
+		"/bibles/de4e12af7f28f599-02/passages/JHN.3.36-JHN.4.2": "apibible_passage.json",
+	})
+	defer ts.Close()
+
+	r, _ := ref.ParseRange("John 3:36-4:2")
+	p, err := NewAPIBible(Config{Key: "secret", BaseURL: ts.URL}).Passage(Query{Range: *r, Translation: "kjv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ref.Ref{
+		*ref.New(ref.John, 3, 36),
+		*ref.New(ref.John, 4, 1),
+		*ref.New(ref.John, 4, 2),
+	}
+	if len(p.Verses) != len(expected) {
+		t.Fatalf("got %d verses, wanted %d", len(p.Verses), len(expected))
+	}
+	for i, v := range p.Verses {
+		if v.Ref != expected[i] {
+			t.Errorf("verse %d: got %v, wanted %v", i, v.Ref, expected[i])
+		}
+	}
+
+	if p.Verses[2].Text != "(Though Jesus himself baptized not, but his disciples,)" {
+		t.Errorf("got text %q", p.Verses[2].Text)
+	}
+
+	v := p.Verses[1]
+	if len(v.Headings) != 1 || v.Headings[0].Text != "Jesus and the Woman of Samaria" || !v.Paragraph {
+		t.Errorf("got headings %+v, paragraph %v", v.Headings, v.Paragraph)
+	}
+
+	if len(v.Footnotes) != 1 || v.Footnotes[0].Text != "Some manuscripts read Jesus" ||
+		v.Footnotes[0].Offset != len(v.Text) {
+		t.Errorf("got footnotes %+v", v.Footnotes)
+	}
+
+	if p.Translation != "KJV" || p.Reference != "John 3:36-4:2" {
+		t.Errorf("got translation %q, reference %q", p.Translation, p.Reference)
+	}
+}
+
+func TestAPIBibleAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("api-key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"statusCode": 401, "error": "Unauthorized", "message": "Invalid API Key"}`))
+			return
+		}
+		http.ServeFile(w, r, "testdata/apibible_bibles.json")
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		key string
+		err error
+	}{
+		{"", ErrUnauthorized},
+		{"wrong", ErrUnauthorized},
+		{"secret", nil},
+	}
+
+	for _, c := range cases {
+		_, err := NewAPIBible(Config{Key: c.key, BaseURL: ts.URL}).Translations()
+		if c.err == nil {
+			if err != nil {
+				t.Errorf("key %q: unexpected error %v", c.key, err)
+			}
+			continue
+		}
+
+		if e, ok := err.(*Error); !ok || e.Err != c.err {
+			t.Errorf("key %q: got error %v, wanted %v", c.key, err, c.err)
+		}
+	}
+}
+
+func TestAPIBiblePassageID(t *testing.T) {
+	cases := []struct {
+		s, id string
+	}{
+		{"John 3:16", "JHN.3.16"},
+		{"1 John 3", "1JN.3"},
+		{"Jude", "JUD.1"},
+		{"Romans 8:28-30", "ROM.8.28-ROM.8.30"},
+		{"Genesis 50-Exodus 2", "GEN.50-EXO.2"},
+	}
+
+	for _, c := range cases {
+		r, err := ref.ParseRange(c.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id := apiBiblePassageID(*r); id != c.id {
+			t.Errorf("apiBiblePassageID(%q) -> %q, wanted %q", c.s, id, c.id)
+		}
+	}
+}
+
+func TestAPIBibleWordsOfJesus(t *testing.T) {
+	var content []apiBibleNode
+	err := json.Unmarshal([]byte(`[{"name": "para", "type": "tag", "attrs": {"style": "p"}, "items": [
+		{"name": "verse", "type": "tag", "attrs": {"number": "25", "style": "v", "sid": "JHN 11:25"}, "items": [{"text": "25", "type": "text"}]},
+		{"text": "Jesus said unto her, ", "type": "text"},
+		{"name": "char", "type": "tag", "attrs": {"style": "wj"}, "items": [{"text": "I am the resurrection, and the life", "type": "text"}]},
+		{"text": ".", "type": "text"}
+	]}]`), &content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := newVerseBuilder(*ref.New(ref.John, 11, 25))
+	for _, n := range content {
+		n.walkPara(b)
+	}
+	verses := b.done()
+
+	if len(verses) != 1 || len(verses[0].WordsOfJesus) != 1 {
+		t.Fatalf("got %+v", verses)
+	}
+
+	v := verses[0]
+	s := v.WordsOfJesus[0]
+	if words := v.Text[s.Start:s.End]; words != "I am the resurrection, and the life" {
+		t.Errorf("got words of Jesus %q in %q", words, v.Text)
+	}
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+const bibleAPIBaseURL = "https://bible-api.com"
+
+// BibleAPI fetches public domain translations from bible-api.com, which needs
+// no credentials
+type BibleAPI struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewBibleAPI returns a bible-api.com provider. The key is not used.
+func NewBibleAPI(c Config) *BibleAPI {
+	b := BibleAPI{baseURL: c.BaseURL, client: http.DefaultClient}
+	if b.baseURL == "" {
+		b.baseURL = bibleAPIBaseURL
+	}
+
+	return &b
+}
+
+// Name returns "bibleapi"
+func (b *BibleAPI) Name() string {
+	return "bibleapi"
+}
+
+type bibleAPIPassage struct {
+	Reference string `json:"reference"`
+	Verses    []struct {
+		BookID  string `json:"book_id"`
+		Chapter int    `json:"chapter"`
+		Verse   int    `json:"verse"`
+		Text    string `json:"text"`
+	} `json:"verses"`
+	TranslationID   string `json:"translation_id"`
+	TranslationNote string `json:"translation_note"`
+}
+
+// Passage fetches a passage. The translation is one of the identifiers
+// returned by Translations, e.g. "kjv"; an empty translation gets the
+// service's default, the World English Bible.
+func (b *BibleAPI) Passage(q Query) (*passage.Passage, error) {
+	u := b.baseURL + "/" + url.PathEscape(q.Range.String())
+	if q.Translation != "" {
+		u += "?" + url.Values{"translation": {strings.ToLower(q.Translation)}}.Encode()
+	}
+
+	var bp bibleAPIPassage
+	if err := b.get(u, &bp); err != nil {
+		return nil, err
+	}
+
+	p := passage.Passage{
+		Range:       q.Range,
+		Reference:   bp.Reference,
+		Translation: strings.ToUpper(bp.TranslationID),
+		Copyright:   bp.TranslationNote,
+	}
+
+	for _, v := range bp.Verses {
+		book, err := ref.BookByUSFM(v.BookID)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Verses = append(p.Verses, passage.Verse{
+			Ref:  *ref.New(book, v.Chapter, v.Verse),
+			Text: cleanText(v.Text),
+		})
+	}
+
+	return &p, nil
+}
+
+// Translations lists the translations the service offers
+func (b *BibleAPI) Translations() ([]Translation, error) {
+	var data struct {
+		Translations []struct {
+			Identifier string `json:"identifier"`
+			Name       string `json:"name"`
+			Language   string `json:"language"`
+		} `json:"translations"`
+	}
+
+	if err := b.get(b.baseURL+"/data", &data); err != nil {
+		return nil, err
+	}
+
+	translations := make([]Translation, len(data.Translations))
+	for i, t := range data.Translations {
+		translations[i] = Translation{
+			ID:           t.Identifier,
+			Abbreviation: strings.ToUpper(t.Identifier),
+			Name:         t.Name,
+			Language:     t.Language,
+		}
+	}
+
+	return translations, nil
+}
+
+// get decodes the JSON response from u into v. Errors come back as
+// {"error": "..."} with a matching status code.
+func (b *BibleAPI) get(u string, v interface{}) error {
+	resp, err := b.client.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return statusError(b.Name(), resp.StatusCode, e.Error)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
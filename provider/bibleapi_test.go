@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dtjm/bible/ref"
+)
+
+// fixtureServer serves testdata files by request path, and 404s for anything
+// else
+func fixtureServer(t *testing.T, fixtures map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, ok := fixtures[r.URL.Path]
+		if !ok {
+			t.Logf("no fixture for %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "not found", "message": "not found"}`))
+			return
+		}
+
+		http.ServeFile(w, r, "testdata/"+file)
+	}))
+}
+
+func TestBibleAPIPassage(t *testing.T) {
+	ts := fixtureServer(t, map[string]string{
+		"/John 3:16-17": "bibleapi_passage.json",
+	})
+	defer ts.Close()
+
+	r, _ := ref.ParseRange("John 3:16-17")
+	p, err := NewBibleAPI(Config{BaseURL: ts.URL}).Passage(Query{Range: *r, Translation: "KJV"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Translation != "KJV" || p.Copyright != "Public Domain" {
+		t.Errorf("got translation %q, copyright %q", p.Translation, p.Copyright)
+	}
+
+	if len(p.Verses) != 2 {
+		t.Fatalf("got %d verses, wanted 2", len(p.Verses))
+	}
+
+	if v := p.Verses[1]; v.Ref != *ref.New(ref.John, 3, 17) ||
+		v.Text != "For God sent not his Son into the world to condemn the world; but that the world through him might be saved." {
+		t.Errorf("got verse %v %q", v.Ref, v.Text)
+	}
+}
+
+func TestBibleAPIErrors(t *testing.T) {
+	ts := fixtureServer(t, nil)
+	defer ts.Close()
+
+	r, _ := ref.ParseRange("John 3:16")
+	_, err := NewBibleAPI(Config{BaseURL: ts.URL}).Passage(Query{Range: *r})
+	if e, ok := err.(*Error); !ok || e.Err != ErrNotFound || e.Message != "not found" {
+		t.Errorf("got error %v, wanted %v", err, ErrNotFound)
+	}
+}
+
+func TestBibleAPITranslations(t *testing.T) {
+	ts := fixtureServer(t, map[string]string{
+		"/data": "bibleapi_data.json",
+	})
+	defer ts.Close()
+
+	translations, err := NewBibleAPI(Config{BaseURL: ts.URL}).Translations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Translation{
+		{ID: "kjv", Abbreviation: "KJV", Name: "King James Version", Language: "English"},
+		{ID: "web", Abbreviation: "WEB", Name: "World English Bible", Language: "English"},
+	}
+	if len(translations) != len(expected) {
+		t.Fatalf("got %d translations, wanted %d", len(translations), len(expected))
+	}
+	for i := range expected {
+		if translations[i] != expected[i] {
+			t.Errorf("got translation %+v, wanted %+v", translations[i], expected[i])
+		}
+	}
+}
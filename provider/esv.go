@@ -0,0 +1,241 @@
+package provider
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+const esvBaseURL = "http://www.esvapi.org/v2/rest"
+
+var (
+	esvBlockRegex      = regexp.MustCompile("\\n[ \\t]*\\n")
+	esvFootnotesRegex  = regexp.MustCompile("(?m)^Footnotes[ \\t]*$")
+	esvFootnoteRegex   = regexp.MustCompile("(?m)^\\((\\d+)\\)\\s*(?:\\d+:\\d+\\s+)?(.*)$")
+	esvNoteMarkerRegex = regexp.MustCompile("\\((\\d+)\\)")
+)
+
+// ESV fetches the English Standard Version from the ESV Bible Web Service
+type ESV struct {
+	key     string
+	baseURL string
+	client  *http.Client
+}
+
+// NewESV returns an ESV provider. The key defaults to "IP", which the service
+// accepts for low-volume, non-commercial use.
+func NewESV(c Config) *ESV {
+	e := ESV{key: c.Key, baseURL: c.BaseURL, client: http.DefaultClient}
+	if e.key == "" {
+		e.key = "IP"
+	}
+	if e.baseURL == "" {
+		e.baseURL = esvBaseURL
+	}
+
+	return &e
+}
+
+// Name returns "esv"
+func (e *ESV) Name() string {
+	return "esv"
+}
+
+// Passage fetches a passage. The translation is ignored; the service only
+// serves the ESV.
+//
+// Headings and footnotes are passed on to the service, and the plain text it
+// returns is parsed back into verses by parseESVText. Verse numbers are always
+// requested, since that is how verses are told apart; the other options are
+// left to the renderer.
+func (e *ESV) Passage(q Query) (*passage.Passage, error) {
+	query := url.Values{
+		"key":                         {e.key},
+		"output-format":               {"plain-text"},
+		"passage":                     {q.Range.String()},
+		"include-headings":            {esvBool(q.Options.Headings)},
+		"include-subheadings":         {esvBool(q.Options.Subheadings)},
+		"include-passage-references":  {"0"},
+		"include-verse-numbers":       {"1"},
+		"include-first-verse-numbers": {"1"},
+		"include-footnotes":           {esvBool(q.Options.Footnotes)},
+		"include-short-copyright":     {"0"},
+		"line-length":                 {"0"},
+	}
+
+	resp, err := e.client.Get(e.baseURL + "/passageQuery?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// The service reports errors as plain text with a 200 status
+	text := string(body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(e.Name(), resp.StatusCode, cleanText(text))
+	}
+	if strings.HasPrefix(text, "ERROR") {
+		return nil, statusError(e.Name(), http.StatusBadRequest, cleanText(text))
+	}
+
+	verses := parseESVText(text, q.Range.Start)
+	if len(verses) == 0 {
+		return nil, statusError(e.Name(), http.StatusNotFound, "")
+	}
+
+	return &passage.Passage{
+		Range:       q.Range,
+		Reference:   q.Range.String(),
+		Translation: "ESV",
+		Verses:      verses,
+		Copyright:   "Scripture quotations are from the ESV® Bible (The Holy Bible, English Standard Version®), copyright © 2001 by Crossway, a publishing ministry of Good News Publishers. Used by permission. All rights reserved.",
+	}, nil
+}
+
+// parseESVText parses the service's plain-text output, which with a line
+// length of 0 looks like this:
+//
+//	  For God So Loved the World
+//
+//	  [16] "For God so loved the world,(1) that he gave his only Son ...
+//
+//	    [1] Blessed is the man
+//	    who walks not in the counsel of the wicked,
+//
+//	Footnotes
+//
+//	(1) 3:16 Or *For this is how God loved the world*
+//
+// Blocks are separated by blank lines. A block with no verse numbers is a
+// heading, unless it is indented as poetry, in which case it carries on the
+// verse before it. Lines indented by four or more spaces are poetry, and
+// "(1)" marks where the first footnote goes.
+func parseESVText(text string, start ref.Ref) []passage.Verse {
+	notes := make(map[string]string)
+	if loc := esvFootnotesRegex.FindStringIndex(text); loc != nil {
+		for _, m := range esvFootnoteRegex.FindAllStringSubmatch(text[loc[1]:], -1) {
+			notes[m[1]] = m[2]
+		}
+		text = text[:loc[0]]
+	}
+
+	b := newVerseBuilder(start)
+	for _, block := range esvBlockRegex.Split(text, -1) {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+
+		if !verseMarkerRegex.MatchString(block) && esvIndent(block) < 4 {
+			b.heading(1, block)
+			continue
+		}
+
+		b.breakParagraph()
+		for _, line := range strings.Split(block, "\n") {
+			poetry := esvIndent(line) >= 4
+			if poetry {
+				b.breakLine()
+			}
+
+			last := 0
+			for _, m := range verseMarkerRegex.FindAllStringSubmatchIndex(line, -1) {
+				esvText(b, line[last:m[0]], notes)
+				if m[4] >= 0 {
+					b.verse(atoi(line[m[2]:m[3]]), atoi(line[m[4]:m[5]]), poetry)
+				} else {
+					b.verse(0, atoi(line[m[2]:m[3]]), poetry)
+				}
+				last = m[1]
+			}
+			esvText(b, line[last:]+" ", notes)
+		}
+	}
+
+	return b.done()
+}
+
+// esvText adds text to the current verse, turning footnote markers into notes
+func esvText(b *verseBuilder, s string, notes map[string]string) {
+	last := 0
+	for _, m := range esvNoteMarkerRegex.FindAllStringSubmatchIndex(s, -1) {
+		note, ok := notes[s[m[2]:m[3]]]
+		if !ok {
+			continue
+		}
+
+		b.text(s[last:m[0]])
+		b.footnote(note)
+		last = m[1]
+	}
+	b.text(s[last:])
+}
+
+// esvIndent counts the spaces at the start of s
+func esvIndent(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
+
+// Search finds verses containing all of the words
+func (e *ESV) Search(words string) ([]SearchResult, error) {
+	query := url.Values{
+		"key":              {e.key},
+		"words":            {words},
+		"search-text":      {"text"},
+		"results-per-page": {"100"},
+	}
+
+	resp, err := e.client.Get(e.baseURL + "/query?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(e.Name(), resp.StatusCode, "")
+	}
+
+	doc, err := goquery.NewDocumentFromResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	doc.Find("span.show-me").Remove()
+
+	var results []SearchResult
+	doc.Find("p.search-result").Each(func(_ int, s *goquery.Selection) {
+		results = append(results, SearchResult{
+			Reference: s.Find("a").First().Remove().Text(),
+			Text:      cleanText(s.Text()),
+		})
+	})
+
+	return results, nil
+}
+
+func esvBool(b bool) string {
+	if b {
+		return "1"
+	}
+
+	return "0"
+}
+
+// Translations returns the ESV, which is the only translation offered
+func (e *ESV) Translations() ([]Translation, error) {
+	return []Translation{{
+		ID:           "ESV",
+		Abbreviation: "ESV",
+		Name:         "English Standard Version",
+		Language:     "English",
+	}}, nil
+}
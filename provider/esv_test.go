@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+func TestESVPassage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("include-headings") != "1" || r.URL.Query().Get("include-verse-numbers") != "1" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+
+		switch r.URL.Query().Get("passage") {
+		case "John 3:16-17":
+			http.ServeFile(w, r, "testdata/esv_passage.txt")
+		default:
+			w.Write([]byte("ERROR: No results were found for your search."))
+		}
+	}))
+	defer ts.Close()
+
+	esv := NewESV(Config{BaseURL: ts.URL})
+
+	r, _ := ref.ParseRange("John 3:16-17")
+	p, err := esv.Passage(Query{Range: *r, Options: passage.Options{Headings: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.Verses) != 2 || p.Verses[1].Ref != *ref.New(ref.John, 3, 17) {
+		t.Fatalf("got verses %+v", p.Verses)
+	}
+
+	v := p.Verses[0]
+	if v.Text != `"For God so loved the world, that he gave his only Son, that whoever believes in him should not perish but have eternal life.` {
+		t.Errorf("got text %q", v.Text)
+	}
+
+	if len(v.Headings) != 1 || v.Headings[0].Text != "For God So Loved the World" {
+		t.Errorf("got headings %+v", v.Headings)
+	}
+
+	expected := passage.Footnote{Offset: 28, Text: "Or *For this is how God loved the world*"}
+	if len(v.Footnotes) != 1 || v.Footnotes[0] != expected {
+		t.Errorf("got footnotes %+v, wanted %+v", v.Footnotes, expected)
+	}
+
+	r, _ = ref.ParseRange("John 30")
+	_, err = esv.Passage(Query{Range: *r, Options: passage.Options{Headings: true}})
+	if e, ok := err.(*Error); !ok || e.Err != ErrBadRequest {
+		t.Errorf("got error %v, wanted %v", err, ErrBadRequest)
+	}
+}
+
+func TestParseESVText(t *testing.T) {
+	text := "  [35] First.\n\n    [36] Second\n    line.\n\n    Stanza.\n\n  [1] Third. [5:2] Fourth.\n"
+	verses := parseESVText(text, *ref.New(ref.John, 3, 35))
+
+	expected := []passage.Verse{
+		{Ref: *ref.New(ref.John, 3, 35), Text: "First.", Paragraph: true},
+		{Ref: *ref.New(ref.John, 3, 36), Text: "Second\nline.\nStanza.", Paragraph: true, Poetry: true},
+		{Ref: *ref.New(ref.John, 4, 1), Text: "Third.", Paragraph: true},
+		{Ref: *ref.New(ref.John, 5, 2), Text: "Fourth."},
+	}
+
+	if len(verses) != len(expected) {
+		t.Fatalf("got %d verses, wanted %d", len(verses), len(expected))
+	}
+
+	for i, e := range expected {
+		v := verses[i]
+		if v.Ref != e.Ref || v.Text != e.Text || v.Paragraph != e.Paragraph || v.Poetry != e.Poetry {
+			t.Errorf("verse %d: got %+v, wanted %+v", i, v, e)
+		}
+	}
+}
+
+func TestESVSearch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/query" || r.URL.Query().Get("words") != "money" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		http.ServeFile(w, r, "testdata/esv_search.html")
+	}))
+	defer ts.Close()
+
+	results, err := NewESV(Config{BaseURL: ts.URL}).Search("money")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SearchResult{
+		{"Matthew 6:24", "“No one can serve two masters, for either he will hate the one and love the other, or he will be devoted to the one and despise the other. You cannot serve God and money."},
+		{"1 Timothy 6:10", "For the love of money is a root of all kinds of evils."},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("got %d results, wanted %d", len(results), len(expected))
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("got %+v, wanted %+v", results[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package provider fetches scripture from online Bible services and converts
+// it into the passage model.
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+// Provider is an online source of scripture
+type Provider interface {
+	// Name returns the name the provider is configured under, e.g. "esv"
+	Name() string
+
+	// Passage fetches the text of a passage
+	Passage(q Query) (*passage.Passage, error)
+
+	// Translations lists the translations the provider can serve
+	Translations() ([]Translation, error)
+}
+
+// Searcher is implemented by providers that can search the text
+type Searcher interface {
+	Search(words string) ([]SearchResult, error)
+}
+
+// SearchResult is a verse matching a search
+type SearchResult struct {
+	Reference string
+	Text      string
+}
+
+// Query describes a passage to fetch
+type Query struct {
+	Range       ref.Range
+	Translation string
+	Options     passage.Options
+}
+
+// Translation is a version of the Bible offered by a provider
+type Translation struct {
+	// ID is the provider's identifier for the translation, which is what
+	// Query.Translation expects
+	ID           string
+	Abbreviation string
+	Name         string
+	Language     string
+}
+
+// Config holds the settings for a single provider, as read from the
+// [providers.<name>] section of the config file
+type Config struct {
+	Key     string `toml:"key"`
+	BaseURL string `toml:"base_url"`
+}
+
+// Errors returned by providers, wrapped in an *Error
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("passage or translation not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrUnavailable  = errors.New("service unavailable")
+)
+
+// Error is an error reported by a provider's API
+type Error struct {
+	Provider   string
+	StatusCode int
+	Message    string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s: %s", e.Provider, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", e.Provider, e.Err, e.Message)
+}
+
+// Unwrap returns the underlying Err* value
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// statusError maps an HTTP status code onto one of the Err* values
+func statusError(provider string, status int, message string) error {
+	var err error
+	switch {
+	case status == http.StatusBadRequest:
+		err = ErrBadRequest
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		err = ErrUnauthorized
+	case status == http.StatusNotFound:
+		err = ErrNotFound
+	case status == http.StatusTooManyRequests:
+		err = ErrRateLimited
+	default:
+		err = ErrUnavailable
+	}
+
+	return &Error{
+		Provider:   provider,
+		StatusCode: status,
+		Message:    message,
+		Err:        err,
+	}
+}
+
+// New returns the provider registered under name
+func New(name string, c Config) (Provider, error) {
+	switch strings.ToLower(name) {
+	case "esv":
+		return NewESV(c), nil
+	case "bibleapi":
+		return NewBibleAPI(c), nil
+	case "apibible":
+		return NewAPIBible(c), nil
+	}
+
+	return nil, fmt.Errorf("Unknown provider: %q", name)
+}
+
+// Names lists the names of every provider New knows about
+func Names() []string {
+	return []string{"esv", "bibleapi", "apibible"}
+}
+
+// Resolve picks the provider for a translation spec and returns it with the
+// translation to request from it. A spec is either a bare translation such as
+// "KJV", or one qualified with a provider name such as "apibible:KJV". Bare
+// ESV is served by the ESV provider and everything else by bible-api.com.
+func Resolve(spec string, configs map[string]Config) (Provider, string, error) {
+	name, translation := "", spec
+	if i := strings.Index(spec, ":"); i >= 0 {
+		name, translation = spec[:i], spec[i+1:]
+	}
+
+	if name == "" {
+		if strings.EqualFold(translation, "ESV") {
+			name = "esv"
+		} else {
+			name = "bibleapi"
+		}
+	}
+
+	p, err := New(name, configs[strings.ToLower(name)])
+	if err != nil {
+		return nil, "", err
+	}
+
+	return p, translation, nil
+}
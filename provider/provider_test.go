@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	cases := []struct {
+		spec, provider, translation string
+	}{
+		{"ESV", "esv", "ESV"},
+		{"kjv", "bibleapi", "kjv"},
+		{"apibible:KJV", "apibible", "KJV"},
+	}
+
+	for _, c := range cases {
+		p, translation, err := Resolve(c.spec, nil)
+		if err != nil {
+			t.Errorf("Resolve(%q) error: %q", c.spec, err)
+			continue
+		}
+
+		if p.Name() != c.provider || translation != c.translation {
+			t.Errorf("Resolve(%q) -> %s, %q, wanted %s, %q",
+				c.spec, p.Name(), translation, c.provider, c.translation)
+		}
+	}
+
+	if _, _, err := Resolve("nope:KJV", nil); err == nil {
+		t.Errorf("expected an error for an unknown provider")
+	}
+}
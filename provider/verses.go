@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+var (
+	verseMarkerRegex = regexp.MustCompile("\\[(\\d+)(?::(\\d+))?\\]")
+	whitespaceRegex  = regexp.MustCompile("\\s+")
+)
+
+// verseBuilder accumulates verses as a provider's response is walked in
+// reading order. Headings and paragraph breaks seen between verses are
+// attached to the verse that follows them.
+type verseBuilder struct {
+	book    ref.Book
+	chapter int
+	verses  []passage.Verse
+
+	pending   []passage.Heading
+	paragraph bool
+	newLine   bool
+}
+
+func newVerseBuilder(start ref.Ref) *verseBuilder {
+	b := verseBuilder{book: start.Book(), chapter: start.Chapter()}
+	if b.chapter == 0 {
+		b.chapter = 1
+	}
+
+	return &b
+}
+
+// heading queues a heading for the next verse
+func (b *verseBuilder) heading(level int, text string) {
+	if text = cleanText(text); text != "" {
+		b.pending = append(b.pending, passage.Heading{Level: level, Text: text})
+	}
+}
+
+// breakParagraph starts a new paragraph or stanza. If it falls inside a verse,
+// the verse carries on on a new line.
+func (b *verseBuilder) breakParagraph() {
+	b.paragraph = true
+	b.newLine = true
+}
+
+// breakLine starts a new line of poetry
+func (b *verseBuilder) breakLine() {
+	b.newLine = true
+}
+
+// verse starts a new verse. A zero chapter means the current one, unless the
+// verse number has gone backwards, which means the next one.
+func (b *verseBuilder) verse(chapter, verse int, poetry bool) {
+	if chapter > 0 {
+		b.chapter = chapter
+	} else if n := len(b.verses); n > 0 && verse < b.verses[n-1].Ref.Verse() &&
+		b.verses[n-1].Ref.Chapter() == b.chapter {
+		b.chapter++
+	}
+
+	b.verses = append(b.verses, passage.Verse{
+		Ref:       *ref.New(b.book, b.chapter, verse),
+		Headings:  b.pending,
+		Paragraph: b.paragraph,
+		Poetry:    poetry,
+	})
+
+	b.pending = nil
+	b.paragraph = false
+	b.newLine = false
+}
+
+// text appends text to the current verse, collapsing whitespace. Text before
+// the first verse is discarded.
+func (b *verseBuilder) text(s string) {
+	v := b.current()
+	if v == nil {
+		return
+	}
+
+	s = whitespaceRegex.ReplaceAllString(s, " ")
+	if b.newLine && strings.TrimSpace(s) != "" {
+		v.Text = strings.TrimRight(v.Text, " ")
+		if v.Text != "" && v.Poetry {
+			v.Text += "\n"
+		} else if v.Text != "" {
+			v.Text += " "
+		}
+		b.newLine = false
+	}
+
+	if v.Text == "" || strings.HasSuffix(v.Text, " ") || strings.HasSuffix(v.Text, "\n") {
+		s = strings.TrimLeft(s, " ")
+	}
+	v.Text += s
+}
+
+// startWordsOfJesus marks the start of words spoken by Jesus in the current
+// verse
+func (b *verseBuilder) startWordsOfJesus() {
+	if v := b.current(); v != nil {
+		offset := len(v.Text)
+		v.WordsOfJesus = append(v.WordsOfJesus, passage.Span{Start: offset, End: offset})
+	}
+}
+
+// endWordsOfJesus marks the end of words spoken by Jesus
+func (b *verseBuilder) endWordsOfJesus() {
+	if v := b.current(); v != nil && len(v.WordsOfJesus) > 0 {
+		v.WordsOfJesus[len(v.WordsOfJesus)-1].End = len(strings.TrimRight(v.Text, " "))
+	}
+}
+
+// footnote attaches a note to the current verse at the end of its text so far
+func (b *verseBuilder) footnote(text string) {
+	v := b.current()
+	if v == nil {
+		return
+	}
+
+	if text = cleanText(text); text != "" {
+		offset := len(strings.TrimRight(v.Text, " "))
+		v.Footnotes = append(v.Footnotes, passage.Footnote{Offset: offset, Text: text})
+	}
+}
+
+func (b *verseBuilder) current() *passage.Verse {
+	if len(b.verses) == 0 {
+		return nil
+	}
+
+	return &b.verses[len(b.verses)-1]
+}
+
+// done returns the verses with trailing whitespace removed
+func (b *verseBuilder) done() []passage.Verse {
+	for i := range b.verses {
+		v := &b.verses[i]
+		v.Text = strings.TrimRight(v.Text, " \n")
+		for j := range v.Footnotes {
+			if v.Footnotes[j].Offset > len(v.Text) {
+				v.Footnotes[j].Offset = len(v.Text)
+			}
+		}
+
+		// Drop empty spans and skip any whitespace at the start of the rest
+		spans := v.WordsOfJesus[:0]
+		for _, s := range v.WordsOfJesus {
+			if s.End > len(v.Text) {
+				s.End = len(v.Text)
+			}
+			for s.Start < s.End && (v.Text[s.Start] == ' ' || v.Text[s.Start] == '\n') {
+				s.Start++
+			}
+			if s.Start < s.End {
+				spans = append(spans, s)
+			}
+		}
+		v.WordsOfJesus = spans
+	}
+
+	return b.verses
+}
+
+// cleanText collapses runs of whitespace into single spaces
+func cleanText(s string) string {
+	return strings.TrimSpace(whitespaceRegex.ReplaceAllString(s, " "))
+}
+
+// atoi parses the leading digits of s, so that a verse span such as "16-17"
+// gives 16
+func atoi(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
@@ -0,0 +1,85 @@
+// Package random picks passages of the Bible at random.
+package random
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/dtjm/bible/ref"
+)
+
+// ErrNoneLeft is returned when the filter rules out every passage
+var ErrNoneLeft = errors.New("No passages match the filters")
+
+// Filter narrows down the passages that may be picked
+type Filter struct {
+	// Books to pick from, or every book if empty
+	Books []ref.Book
+
+	// Read, if set, reports whether a chapter has already been read, so
+	// that it can be left out
+	Read func(chapter *ref.Ref) bool
+}
+
+// chapters returns the chapters the filter allows
+func (f Filter) chapters() []ref.Ref {
+	books := f.Books
+	if len(books) == 0 {
+		for b := ref.Genesis; b <= ref.Revelation; b++ {
+			books = append(books, b)
+		}
+	}
+
+	var chapters []ref.Ref
+	seen := make(map[ref.Book]bool)
+	for _, b := range books {
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+
+		for c := 1; c <= b.Chapters(); c++ {
+			chapter := ref.New(b, c, 0)
+			if f.Read == nil || !f.Read(chapter) {
+				chapters = append(chapters, *chapter)
+			}
+		}
+	}
+
+	return chapters
+}
+
+// Chapter picks a chapter, each one as likely as any other
+func Chapter(rng *rand.Rand, f Filter) (*ref.Ref, error) {
+	chapters := f.chapters()
+	if len(chapters) == 0 {
+		return nil, ErrNoneLeft
+	}
+
+	return &chapters[rng.Intn(len(chapters))], nil
+}
+
+// Verse picks a verse, each one as likely as any other. Chapters are weighted
+// by the number of verses in them, so Psalm 119 is far more likely than
+// Psalm 117.
+func Verse(rng *rand.Rand, f Filter) (*ref.Ref, error) {
+	chapters := f.chapters()
+	total := 0
+	for _, c := range chapters {
+		total += c.Book().Verses(c.Chapter())
+	}
+	if total == 0 {
+		return nil, ErrNoneLeft
+	}
+
+	n := rng.Intn(total)
+	for _, c := range chapters {
+		verses := c.Book().Verses(c.Chapter())
+		if n < verses {
+			return ref.New(c.Book(), c.Chapter(), n+1), nil
+		}
+		n -= verses
+	}
+
+	panic("random: verse out of range")
+}
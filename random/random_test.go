@@ -0,0 +1,67 @@
+package random
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/dtjm/bible/ref"
+)
+
+func TestSeed(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		a, _ := Verse(rand.New(rand.NewSource(int64(i))), Filter{})
+		b, _ := Verse(rand.New(rand.NewSource(int64(i))), Filter{})
+		if *a != *b {
+			t.Errorf("seed %d picked %v then %v", i, a, b)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	gospels, _ := ref.Group("gospels")
+	read := func(chapter *ref.Ref) bool {
+		return chapter.Book() != ref.John || chapter.Chapter() != 21
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		v, err := Verse(rng, Filter{Books: gospels})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Book() < ref.Matthew || v.Book() > ref.John {
+			t.Errorf("picked %v from the gospels", v)
+		}
+
+		c, err := Chapter(rng, Filter{Books: gospels, Read: read})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.String() != "John 21" {
+			t.Errorf("picked %v, when John 21 is the only unread chapter", c)
+		}
+	}
+
+	everything := func(*ref.Ref) bool { return true }
+	if _, err := Verse(rng, Filter{Read: everything}); err != ErrNoneLeft {
+		t.Errorf("picking with everything read: %v, wanted ErrNoneLeft", err)
+	}
+}
+
+func TestVerseWeighting(t *testing.T) {
+	psalms := Filter{Books: []ref.Book{ref.Psalm}, Read: func(c *ref.Ref) bool {
+		return c.Chapter() != 117 && c.Chapter() != 119
+	}}
+
+	counts := make(map[int]int)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1780; i++ {
+		v, _ := Verse(rng, psalms)
+		counts[v.Chapter()]++
+	}
+
+	// 176 verses to 2, so about 1760 to 20
+	if counts[119] < 1700 || counts[117] < 5 || counts[117] > 50 {
+		t.Errorf("picked Psalm 119 %d times and Psalm 117 %d times", counts[119], counts[117])
+	}
+}
@@ -1,5 +1,10 @@
 package ref
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Book is a book of the Bible
 type Book int
 
@@ -142,6 +147,75 @@ var (
 		Jude:           1,
 		Revelation:     22,
 	}
+
+	usfmCodes = map[Book]string{
+		Genesis:        "GEN",
+		Exodus:         "EXO",
+		Leviticus:      "LEV",
+		Numbers:        "NUM",
+		Deuteronomy:    "DEU",
+		Joshua:         "JOS",
+		Judges:         "JDG",
+		Ruth:           "RUT",
+		Samuel1:        "1SA",
+		Samuel2:        "2SA",
+		Kings1:         "1KI",
+		Kings2:         "2KI",
+		Chronicles1:    "1CH",
+		Chronicles2:    "2CH",
+		Ezra:           "EZR",
+		Nehemiah:       "NEH",
+		Esther:         "EST",
+		Job:            "JOB",
+		Psalm:          "PSA",
+		Proverbs:       "PRO",
+		Ecclesiastes:   "ECC",
+		SongOfSolomon:  "SNG",
+		Isaiah:         "ISA",
+		Jeremiah:       "JER",
+		Lamentations:   "LAM",
+		Ezekiel:        "EZK",
+		Daniel:         "DAN",
+		Hosea:          "HOS",
+		Joel:           "JOL",
+		Amos:           "AMO",
+		Obadiah:        "OBA",
+		Jonah:          "JON",
+		Micah:          "MIC",
+		Nahum:          "NAM",
+		Habakkuk:       "HAB",
+		Zephaniah:      "ZEP",
+		Haggai:         "HAG",
+		Zechariah:      "ZEC",
+		Malachi:        "MAL",
+		Matthew:        "MAT",
+		Mark:           "MRK",
+		Luke:           "LUK",
+		John:           "JHN",
+		Acts:           "ACT",
+		Romans:         "ROM",
+		Corinthians1:   "1CO",
+		Corinthians2:   "2CO",
+		Galatians:      "GAL",
+		Ephesians:      "EPH",
+		Philippians:    "PHP",
+		Colossians:     "COL",
+		Thessalonians1: "1TH",
+		Thessalonians2: "2TH",
+		Timothy1:       "1TI",
+		Timothy2:       "2TI",
+		Titus:          "TIT",
+		Philemon:       "PHM",
+		Hebrews:        "HEB",
+		James:          "JAS",
+		Peter1:         "1PE",
+		Peter2:         "2PE",
+		John1:          "1JN",
+		John2:          "2JN",
+		John3:          "3JN",
+		Jude:           "JUD",
+		Revelation:     "REV",
+	}
 )
 
 // Next returns the next book of the Bible, wrapping around to the beginning
@@ -154,6 +228,16 @@ func (b Book) Next() Book {
 	return n
 }
 
+// Prev returns the previous book of the Bible, wrapping around to the end
+func (b Book) Prev() Book {
+	p := b - 1
+	if p < Genesis {
+		return Revelation
+	}
+
+	return p
+}
+
 func (b Book) String() string {
 	switch b {
 	case Genesis:
@@ -292,3 +376,25 @@ func (b Book) String() string {
 
 	return ""
 }
+
+// Chapters returns the number of chapters in the book
+func (b Book) Chapters() int {
+	return numChapters[b]
+}
+
+// BookByUSFM returns the book with the given USFM identifier, e.g. "JHN"
+func BookByUSFM(code string) (Book, error) {
+	code = strings.ToUpper(code)
+	for b, c := range usfmCodes {
+		if c == code {
+			return b, nil
+		}
+	}
+
+	return nullBook, fmt.Errorf("Unknown USFM book code: %q", code)
+}
+
+// USFM returns the book's three-character USFM identifier, e.g. "JHN"
+func (b Book) USFM() string {
+	return usfmCodes[b]
+}
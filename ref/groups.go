@@ -0,0 +1,67 @@
+package ref
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Groups are the traditional divisions of the books of the Bible, by name
+var Groups = map[string][]Book{
+	"ot":               booksBetween(Genesis, Malachi),
+	"nt":               booksBetween(Matthew, Revelation),
+	"law":              booksBetween(Genesis, Deuteronomy),
+	"history":          booksBetween(Joshua, Esther),
+	"wisdom":           booksBetween(Job, SongOfSolomon),
+	"prophets":         booksBetween(Isaiah, Malachi),
+	"major-prophets":   booksBetween(Isaiah, Daniel),
+	"minor-prophets":   booksBetween(Hosea, Malachi),
+	"gospels":          booksBetween(Matthew, John),
+	"epistles":         booksBetween(Romans, Jude),
+	"pauline":          booksBetween(Romans, Philemon),
+	"general-epistles": booksBetween(Hebrews, Jude),
+	"apocalyptic":      {Daniel, Revelation},
+}
+
+// Testament returns the books of the Old or New Testament, given as "old",
+// "new", "ot" or "nt"
+func Testament(name string) ([]Book, error) {
+	switch strings.ToLower(name) {
+	case "old", "ot":
+		return Groups["ot"], nil
+	case "new", "nt":
+		return Groups["nt"], nil
+	}
+
+	return nil, fmt.Errorf("Unknown testament %q, expected old or new", name)
+}
+
+// Group returns the books in the named group
+func Group(name string) ([]Book, error) {
+	books, ok := Groups[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("Unknown group of books %q, expected one of %q", name, GroupNames())
+	}
+
+	return books, nil
+}
+
+// GroupNames lists the names of the groups in alphabetical order
+func GroupNames() []string {
+	var names []string
+	for name := range Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func booksBetween(first, last Book) []Book {
+	var books []Book
+	for b := first; b <= last; b++ {
+		books = append(books, b)
+	}
+
+	return books
+}
@@ -0,0 +1,44 @@
+package ref
+
+import (
+	"encoding/json"
+)
+
+type jsonRef struct {
+	Ref     string `json:"ref"`
+	Book    string `json:"book"`
+	USFM    string `json:"usfm"`
+	Chapter int    `json:"chapter,omitempty"`
+	Verse   int    `json:"verse,omitempty"`
+}
+
+// MarshalJSON encodes the reference as an object carrying both the
+// human-readable reference and its parts, e.g.
+//
+//	{"ref": "John 3:16", "book": "John", "usfm": "JHN", "chapter": 3, "verse": 16}
+func (r Ref) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonRef{
+		Ref:     r.String(),
+		Book:    r.book.String(),
+		USFM:    r.book.USFM(),
+		Chapter: r.chapter,
+		Verse:   r.verse,
+	})
+}
+
+// UnmarshalJSON decodes a reference written by MarshalJSON. Only the USFM
+// code, chapter and verse are read.
+func (r *Ref) UnmarshalJSON(data []byte) error {
+	var j jsonRef
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	book, err := BookByUSFM(j.USFM)
+	if err != nil {
+		return err
+	}
+
+	*r = Ref{book: book, chapter: j.Chapter, verse: j.Verse}
+	return nil
+}
@@ -0,0 +1,92 @@
+package ref
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Range is an inclusive span of the Bible between two references
+type Range struct {
+	Start Ref `json:"start"`
+	End   Ref `json:"end"`
+}
+
+var (
+	rangeEndVerseRegex  = regexp.MustCompile("^(\\d+)\\s*[:.]\\s*(\\d+)$")
+	rangeEndNumberRegex = regexp.MustCompile("^(\\d+)$")
+	rangeSeparatorRegex = regexp.MustCompile("\\s*[-–—]\\s*")
+)
+
+// ParseRange parses a reference such as "John 3:16-18", "John 3-4",
+// "John 3:16-4:2" or "Genesis 50-Exodus 2". A single reference yields a range
+// whose start and end are the same.
+func ParseRange(s string) (*Range, error) {
+	s = strings.TrimSpace(s)
+	parts := rangeSeparatorRegex.Split(s, 2)
+
+	start, err := Parse(parts[0])
+	if err != nil {
+		return &Range{}, err
+	}
+
+	r := Range{Start: *start, End: *start}
+	if len(parts) == 1 {
+		return &r, nil
+	}
+
+	end := parts[1]
+	switch {
+	case rangeEndVerseRegex.MatchString(end):
+		m := rangeEndVerseRegex.FindStringSubmatch(end)
+		r.End.chapter, _ = strconv.Atoi(m[1])
+		r.End.verse, _ = strconv.Atoi(m[2])
+
+	case rangeEndNumberRegex.MatchString(end):
+		n, _ := strconv.Atoi(end)
+		if start.verse > 0 {
+			r.End.verse = n
+		} else {
+			r.End.chapter = n
+		}
+
+	default:
+		e, err := Parse(end)
+		if err != nil {
+			return &Range{}, err
+		}
+		r.End = *e
+	}
+
+	if r.End.Less(&r.Start) {
+		return &Range{}, fmt.Errorf("Range ends before it starts: %q", s)
+	}
+
+	return &r, nil
+}
+
+func (r *Range) String() string {
+	return r.format(func(r *Ref) string { return r.String() })
+}
+
+// format writes the range, with each end that names its book written by ref
+func (r *Range) format(ref func(*Ref) string) string {
+	if r.Start == r.End {
+		return ref(&r.Start)
+	}
+
+	if r.Start.book != r.End.book {
+		return ref(&r.Start) + "-" + ref(&r.End)
+	}
+
+	switch {
+	case r.Start.chapter == r.End.chapter && r.Start.verse > 0 && r.End.verse > 0:
+		return fmt.Sprintf("%s-%d", ref(&r.Start), r.End.verse)
+
+	case r.Start.verse == 0 && r.End.verse == 0:
+		return fmt.Sprintf("%s-%d", ref(&r.Start), r.End.chapter)
+	}
+
+	return fmt.Sprintf("%s-%d:%d", ref(&r.Start), r.End.chapter, r.End.verse)
+}
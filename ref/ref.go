@@ -14,89 +14,87 @@ type Ref struct {
 }
 
 var bookRegex = map[*regexp.Regexp]Book{
-	regexp.MustCompile("(?i)^ge\\w*\\s*(\\d+)?"):      Genesis,
-	regexp.MustCompile("(?i)^ex\\w*\\s*(\\d+)?"):      Exodus,
-	regexp.MustCompile("(?i)^le\\w*\\s*(\\d+)?"):      Leviticus,
-	regexp.MustCompile("(?i)^nu\\w*\\s*(\\d+)?"):      Numbers,
-	regexp.MustCompile("(?i)^de\\w*\\s*(\\d+)?"):      Deuteronomy,
-	regexp.MustCompile("(?i)^jos\\w*\\s*(\\d+)?"):     Joshua,
-	regexp.MustCompile("(?i)^judg\\w*\\s*(\\d+)?"):    Judges,
-	regexp.MustCompile("(?i)^ru\\w*\\s*(\\d+)?"):      Ruth,
-	regexp.MustCompile("(?i)^1\\s?sa\\w*\\s*(\\d+)?"): Samuel1,
-	regexp.MustCompile("(?i)^2\\s?sa\\w*\\s*(\\d+)?"): Samuel2,
-	regexp.MustCompile("(?i)^1\\s?ki\\w*\\s*(\\d+)?"): Kings1,
-	regexp.MustCompile("(?i)^2\\s?ki\\w*\\s*(\\d+)?"): Kings2,
-	regexp.MustCompile("(?i)^1\\s?ch\\w*\\s*(\\d+)?"): Chronicles1,
-	regexp.MustCompile("(?i)^2\\s?ch\\w*\\s*(\\d+)?"): Chronicles2,
-	regexp.MustCompile("(?i)^ez\\w*\\s*(\\d+)?"):      Ezra,
-	regexp.MustCompile("(?i)^ne\\w*\\s*(\\d+)?"):      Nehemiah,
-	regexp.MustCompile("(?i)^es\\w*\\s*(\\d+)?"):      Esther,
-	regexp.MustCompile("(?i)^job\\w*\\s*(\\d+)?"):     Job,
-	regexp.MustCompile("(?i)^ps\\w*\\s*(\\d+)?"):      Psalm,
-	regexp.MustCompile("(?i)^pr\\w*\\s*(\\d+)?"):      Proverbs,
-	regexp.MustCompile("(?i)^ec\\w*\\s*(\\d+)?"):      Ecclesiastes,
-	regexp.MustCompile("(?i)^so\\w*\\s*(\\d+)?"):      SongOfSolomon,
-	regexp.MustCompile("(?i)^is\\w*\\s*(\\d+)?"):      Isaiah,
-	regexp.MustCompile("(?i)^je\\w*\\s*(\\d+)?"):      Jeremiah,
-	regexp.MustCompile("(?i)^la\\w*\\s*(\\d+)?"):      Lamentations,
-	regexp.MustCompile("(?i)^ez\\w*\\s*(\\d+)?"):      Ezekiel,
-	regexp.MustCompile("(?i)^da\\w*\\s*(\\d+)?"):      Daniel,
-	regexp.MustCompile("(?i)^ho\\w*\\s*(\\d+)?"):      Hosea,
-	regexp.MustCompile("(?i)^joe\\w*\\s*(\\d+)?"):     Joel,
-	regexp.MustCompile("(?i)^am\\w*\\s*(\\d+)?"):      Amos,
-	regexp.MustCompile("(?i)^ob\\w*\\s*(\\d+)?"):      Obadiah,
-	regexp.MustCompile("(?i)^jon\\w*\\s*(\\d+)?"):     Jonah,
-	regexp.MustCompile("(?i)^mi\\w*\\s*(\\d+)?"):      Micah,
-	regexp.MustCompile("(?i)^na\\w*\\s*(\\d+)?"):      Nahum,
-	regexp.MustCompile("(?i)^ha\\w*\\s*(\\d+)?"):      Habakkuk,
-	regexp.MustCompile("(?i)^ze\\w*\\s*(\\d+)?"):      Zephaniah,
-	regexp.MustCompile("(?i)^ha\\w*\\s*(\\d+)?"):      Haggai,
-	regexp.MustCompile("(?i)^ze\\w*\\s*(\\d+)?"):      Zechariah,
-	regexp.MustCompile("(?i)^mal\\w*\\s*(\\d+)?"):     Malachi,
-	regexp.MustCompile("(?i)^mat\\w*\\s*(\\d+)?"):     Matthew,
-	regexp.MustCompile("(?i)^mar\\w*\\s*(\\d+)?"):     Mark,
-	regexp.MustCompile("(?i)^lu\\w*\\s*(\\d+)?"):      Luke,
-	regexp.MustCompile("(?i)^joh\\w*\\s*(\\d+)?"):     John,
-	regexp.MustCompile("(?i)^ac\\w*\\s*(\\d+)?"):      Acts,
-	regexp.MustCompile("(?i)^ro\\w*\\s*(\\d+)?"):      Romans,
-	regexp.MustCompile("(?i)^1\\s?co\\w*\\s*(\\d+)?"): Corinthians1,
-	regexp.MustCompile("(?i)^2\\s?co\\w*\\s*(\\d+)?"): Corinthians2,
-	regexp.MustCompile("(?i)^ga\\w*\\s*(\\d+)?"):      Galatians,
-	regexp.MustCompile("(?i)^ep\\w*\\s*(\\d+)?"):      Ephesians,
-	regexp.MustCompile("(?i)^ph\\w*\\s*(\\d+)?"):      Philippians,
-	regexp.MustCompile("(?i)^co\\w*\\s*(\\d+)?"):      Colossians,
-	regexp.MustCompile("(?i)^1\\s?th\\w*\\s*(\\d+)?"): Thessalonians1,
-	regexp.MustCompile("(?i)^2\\s?th\\w*\\s*(\\d+)?"): Thessalonians2,
-	regexp.MustCompile("(?i)^1\\s?ti\\w*\\s*(\\d+)?"): Timothy1,
-	regexp.MustCompile("(?i)^2\\s?ti\\w*\\s*(\\d+)?"): Timothy2,
-	regexp.MustCompile("(?i)^ti\\w*\\s*(\\d+)?"):      Titus,
-	regexp.MustCompile("(?i)^ph\\w*\\s*(\\d+)?"):      Philemon,
-	regexp.MustCompile("(?i)^he\\w*\\s*(\\d+)?"):      Hebrews,
-	regexp.MustCompile("(?i)^ja\\w*\\s*(\\d+)?"):      James,
-	regexp.MustCompile("(?i)^1\\s?pe\\w*\\s*(\\d+)?"): Peter1,
-	regexp.MustCompile("(?i)^2\\s?pe\\w*\\s*(\\d+)?"): Peter2,
-	regexp.MustCompile("(?i)^1\\s?jo\\w*\\s*(\\d+)?"): John1,
-	regexp.MustCompile("(?i)^2\\s?jo\\w*\\s*(\\d+)?"): John2,
-	regexp.MustCompile("(?i)^3\\s?jo\\w*\\s*(\\d+)?"): John3,
-	regexp.MustCompile("(?i)^jude\\w*\\s*(\\d+)?"):    Jude,
-	regexp.MustCompile("(?i)^re\\w*\\s*(\\d+)?"):      Revelation,
+	regexp.MustCompile("(?i)^ge\\w*\\s*(\\d+)?"):                    Genesis,
+	regexp.MustCompile("(?i)^ex\\w*\\s*(\\d+)?"):                    Exodus,
+	regexp.MustCompile("(?i)^le\\w*\\s*(\\d+)?"):                    Leviticus,
+	regexp.MustCompile("(?i)^nu\\w*\\s*(\\d+)?"):                    Numbers,
+	regexp.MustCompile("(?i)^de\\w*\\s*(\\d+)?"):                    Deuteronomy,
+	regexp.MustCompile("(?i)^jos\\w*\\s*(\\d+)?"):                   Joshua,
+	regexp.MustCompile("(?i)^judg\\w*\\s*(\\d+)?"):                  Judges,
+	regexp.MustCompile("(?i)^ru\\w*\\s*(\\d+)?"):                    Ruth,
+	regexp.MustCompile("(?i)^1\\s?sa\\w*\\s*(\\d+)?"):               Samuel1,
+	regexp.MustCompile("(?i)^2\\s?sa\\w*\\s*(\\d+)?"):               Samuel2,
+	regexp.MustCompile("(?i)^1\\s?ki\\w*\\s*(\\d+)?"):               Kings1,
+	regexp.MustCompile("(?i)^2\\s?ki\\w*\\s*(\\d+)?"):               Kings2,
+	regexp.MustCompile("(?i)^1\\s?ch\\w*\\s*(\\d+)?"):               Chronicles1,
+	regexp.MustCompile("(?i)^2\\s?ch\\w*\\s*(\\d+)?"):               Chronicles2,
+	regexp.MustCompile("(?i)^ezr\\w*\\s*(\\d+)?"):                   Ezra,
+	regexp.MustCompile("(?i)^ne\\w*\\s*(\\d+)?"):                    Nehemiah,
+	regexp.MustCompile("(?i)^es\\w*\\s*(\\d+)?"):                    Esther,
+	regexp.MustCompile("(?i)^job\\w*\\s*(\\d+)?"):                   Job,
+	regexp.MustCompile("(?i)^ps\\w*\\s*(\\d+)?"):                    Psalm,
+	regexp.MustCompile("(?i)^pr\\w*\\s*(\\d+)?"):                    Proverbs,
+	regexp.MustCompile("(?i)^ec\\w*\\s*(\\d+)?"):                    Ecclesiastes,
+	regexp.MustCompile("(?i)^so\\w*\\s*(\\d+)?"):                    SongOfSolomon,
+	regexp.MustCompile("(?i)^is\\w*\\s*(\\d+)?"):                    Isaiah,
+	regexp.MustCompile("(?i)^je\\w*\\s*(\\d+)?"):                    Jeremiah,
+	regexp.MustCompile("(?i)^la\\w*\\s*(\\d+)?"):                    Lamentations,
+	regexp.MustCompile("(?i)^ez[ek]\\w*\\s*(\\d+)?"):                Ezekiel,
+	regexp.MustCompile("(?i)^da\\w*\\s*(\\d+)?"):                    Daniel,
+	regexp.MustCompile("(?i)^ho\\w*\\s*(\\d+)?"):                    Hosea,
+	regexp.MustCompile("(?i)^joe\\w*\\s*(\\d+)?"):                   Joel,
+	regexp.MustCompile("(?i)^am\\w*\\s*(\\d+)?"):                    Amos,
+	regexp.MustCompile("(?i)^ob\\w*\\s*(\\d+)?"):                    Obadiah,
+	regexp.MustCompile("(?i)^jon\\w*\\s*(\\d+)?"):                   Jonah,
+	regexp.MustCompile("(?i)^mi\\w*\\s*(\\d+)?"):                    Micah,
+	regexp.MustCompile("(?i)^na\\w*\\s*(\\d+)?"):                    Nahum,
+	regexp.MustCompile("(?i)^hab\\w*\\s*(\\d+)?"):                   Habakkuk,
+	regexp.MustCompile("(?i)^zep\\w*\\s*(\\d+)?"):                   Zephaniah,
+	regexp.MustCompile("(?i)^hag\\w*\\s*(\\d+)?"):                   Haggai,
+	regexp.MustCompile("(?i)^zec\\w*\\s*(\\d+)?"):                   Zechariah,
+	regexp.MustCompile("(?i)^mal\\w*\\s*(\\d+)?"):                   Malachi,
+	regexp.MustCompile("(?i)^mat\\w*\\s*(\\d+)?"):                   Matthew,
+	regexp.MustCompile("(?i)^mar\\w*\\s*(\\d+)?"):                   Mark,
+	regexp.MustCompile("(?i)^lu\\w*\\s*(\\d+)?"):                    Luke,
+	regexp.MustCompile("(?i)^joh\\w*\\s*(\\d+)?"):                   John,
+	regexp.MustCompile("(?i)^ac\\w*\\s*(\\d+)?"):                    Acts,
+	regexp.MustCompile("(?i)^ro\\w*\\s*(\\d+)?"):                    Romans,
+	regexp.MustCompile("(?i)^1\\s?co\\w*\\s*(\\d+)?"):               Corinthians1,
+	regexp.MustCompile("(?i)^2\\s?co\\w*\\s*(\\d+)?"):               Corinthians2,
+	regexp.MustCompile("(?i)^ga\\w*\\s*(\\d+)?"):                    Galatians,
+	regexp.MustCompile("(?i)^ep\\w*\\s*(\\d+)?"):                    Ephesians,
+	regexp.MustCompile("(?i)^(?:php|phil(?:[ip]\\w*)?)\\s*(\\d+)?"): Philippians,
+	regexp.MustCompile("(?i)^co\\w*\\s*(\\d+)?"):                    Colossians,
+	regexp.MustCompile("(?i)^1\\s?th\\w*\\s*(\\d+)?"):               Thessalonians1,
+	regexp.MustCompile("(?i)^2\\s?th\\w*\\s*(\\d+)?"):               Thessalonians2,
+	regexp.MustCompile("(?i)^1\\s?ti\\w*\\s*(\\d+)?"):               Timothy1,
+	regexp.MustCompile("(?i)^2\\s?ti\\w*\\s*(\\d+)?"):               Timothy2,
+	regexp.MustCompile("(?i)^ti\\w*\\s*(\\d+)?"):                    Titus,
+	regexp.MustCompile("(?i)^(?:phm|phil?e)\\w*\\s*(\\d+)?"):        Philemon,
+	regexp.MustCompile("(?i)^he\\w*\\s*(\\d+)?"):                    Hebrews,
+	regexp.MustCompile("(?i)^ja\\w*\\s*(\\d+)?"):                    James,
+	regexp.MustCompile("(?i)^1\\s?pe\\w*\\s*(\\d+)?"):               Peter1,
+	regexp.MustCompile("(?i)^2\\s?pe\\w*\\s*(\\d+)?"):               Peter2,
+	regexp.MustCompile("(?i)^1\\s?jo\\w*\\s*(\\d+)?"):               John1,
+	regexp.MustCompile("(?i)^2\\s?jo\\w*\\s*(\\d+)?"):               John2,
+	regexp.MustCompile("(?i)^3\\s?jo\\w*\\s*(\\d+)?"):               John3,
+	regexp.MustCompile("(?i)^jude\\w*\\s*(\\d+)?"):                  Jude,
+	regexp.MustCompile("(?i)^re\\w*\\s*(\\d+)?"):                    Revelation,
 }
 
-// Parse takes a passage reference and returns a Ref object
+var verseRegex = regexp.MustCompile("^\\s*[:.]\\s*(\\d+)")
+
+// Parse takes a passage reference and returns a Ref object. When more than one
+// book pattern matches, the longest match wins, so "Philemon" is not mistaken
+// for "Phil".
 func Parse(s string) (*Ref, error) {
 	book := nullBook
-	chapter := 0
-	var err error
+	var matches []string
 	for re, b := range bookRegex {
-		if matches := re.FindStringSubmatch(s); len(matches) > 0 {
-			// log.Printf("got matches for %q: %q", re.String(), matches)
+		if m := re.FindStringSubmatch(s); len(m) > 0 && (matches == nil || len(m[0]) > len(matches[0])) {
+			// log.Printf("got matches for %q: %q", re.String(), m)
 			book = b
-			if len(matches) > 1 && matches[1] != "" {
-				chapter, err = strconv.Atoi(matches[1])
-				if err != nil {
-					return &Ref{}, err
-				}
-			}
+			matches = m
 		}
 	}
 
@@ -104,10 +102,29 @@ func Parse(s string) (*Ref, error) {
 		return &Ref{}, fmt.Errorf("Error parsing ref string: %q", s)
 	}
 
-	return &Ref{
-		book:    book,
-		chapter: chapter,
-	}, nil
+	r := Ref{book: book}
+	if len(matches) > 1 && matches[1] != "" {
+		var err error
+		r.chapter, err = strconv.Atoi(matches[1])
+		if err != nil {
+			return &Ref{}, err
+		}
+
+		if vm := verseRegex.FindStringSubmatch(s[len(matches[0]):]); len(vm) > 1 {
+			r.verse, err = strconv.Atoi(vm[1])
+			if err != nil {
+				return &Ref{}, err
+			}
+		}
+	}
+
+	return &r, nil
+}
+
+// New returns a reference to the given book, chapter and verse. A zero
+// chapter refers to the whole book and a zero verse to the whole chapter.
+func New(book Book, chapter, verse int) *Ref {
+	return &Ref{book: book, chapter: chapter, verse: verse}
 }
 
 // Book returns the Book
@@ -115,6 +132,30 @@ func (r *Ref) Book() Book {
 	return r.book
 }
 
+// Chapter returns the chapter number, or 0 if the reference is to a whole book
+func (r *Ref) Chapter() int {
+	return r.chapter
+}
+
+// Verse returns the verse number, or 0 if the reference is to a whole chapter
+func (r *Ref) Verse() int {
+	return r.verse
+}
+
+// Less reports whether r comes before o in canonical order. A reference to a
+// whole chapter or book sorts before the verses inside it.
+func (r *Ref) Less(o *Ref) bool {
+	if r.book != o.book {
+		return r.book < o.book
+	}
+
+	if r.chapter != o.chapter {
+		return r.chapter < o.chapter
+	}
+
+	return r.verse < o.verse
+}
+
 func (r *Ref) String() string {
 	buf := bytes.NewBuffer(nil)
 	buf.WriteString(r.book.String())
@@ -144,3 +185,18 @@ func (r *Ref) NextChapter() *Ref {
 
 	return &nextRef
 }
+
+// PrevChapter returns the previous chapter for a given reference
+func (r *Ref) PrevChapter() *Ref {
+	prevRef := Ref{
+		book:    r.book,
+		chapter: r.chapter - 1,
+	}
+
+	if prevRef.chapter < 1 {
+		prevRef.book = r.book.Prev()
+		prevRef.chapter = numChapters[prevRef.book]
+	}
+
+	return &prevRef
+}
@@ -1,6 +1,7 @@
 package ref
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -12,6 +13,13 @@ func TestParse(t *testing.T) {
 		{"gen 1", Ref{book: Genesis, chapter: 1}},
 		{"1timo", Ref{book: Timothy1}},
 		{"1 John 3", Ref{book: John1, chapter: 3}},
+		{"John 3:16", Ref{book: John, chapter: 3, verse: 16}},
+		{"Ezekiel 37", Ref{book: Ezekiel, chapter: 37}},
+		{"Ezra 1", Ref{book: Ezra, chapter: 1}},
+		{"Haggai 2", Ref{book: Haggai, chapter: 2}},
+		{"Zech 4.6", Ref{book: Zechariah, chapter: 4, verse: 6}},
+		{"Phil 4:13", Ref{book: Philippians, chapter: 4, verse: 13}},
+		{"Philemon 1", Ref{book: Philemon, chapter: 1}},
 	}
 
 	for _, c := range cases {
@@ -67,6 +75,50 @@ func TestChapterNext(t *testing.T) {
 	}
 }
 
+func TestBookPrev(t *testing.T) {
+	cases := []struct {
+		book, prev Book
+	}{
+		{Exodus, Genesis},
+		{Genesis, Revelation},
+		{Matthew, Malachi},
+	}
+
+	for _, c := range cases {
+		if c.book.Prev() != c.prev {
+			t.Errorf("(%s).Prev -> %s, wanted %s ",
+				c.book.String(), c.book.Prev().String(), c.prev.String())
+		}
+	}
+}
+
+func TestChapterPrev(t *testing.T) {
+	cases := []struct {
+		ref, prev Ref
+	}{
+		{
+			Ref{book: Genesis, chapter: 2},
+			Ref{book: Genesis, chapter: 1},
+		},
+		{
+			Ref{book: Exodus, chapter: 1},
+			Ref{book: Genesis, chapter: 50},
+		},
+		{
+			Ref{book: Genesis, chapter: 1},
+			Ref{book: Revelation, chapter: 22},
+		},
+	}
+
+	for _, c := range cases {
+		prevRef := c.ref.PrevChapter()
+		if *prevRef != c.prev {
+			t.Errorf("(%v).PrevChapter -> %v, wanted %v",
+				c.ref, prevRef, c.prev)
+		}
+	}
+}
+
 func TestString(t *testing.T) {
 	cases := []struct {
 		ref Ref
@@ -84,3 +136,264 @@ func TestString(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		s   string
+		r   Range
+		out string
+	}{
+		{
+			"John 3:16-18",
+			Range{Ref{book: John, chapter: 3, verse: 16}, Ref{book: John, chapter: 3, verse: 18}},
+			"John 3:16-18",
+		},
+		{
+			"john 3 - 4",
+			Range{Ref{book: John, chapter: 3}, Ref{book: John, chapter: 4}},
+			"John 3-4",
+		},
+		{
+			"John 3:16-4:2",
+			Range{Ref{book: John, chapter: 3, verse: 16}, Ref{book: John, chapter: 4, verse: 2}},
+			"John 3:16-4:2",
+		},
+		{
+			"Gen 50-Exodus 2",
+			Range{Ref{book: Genesis, chapter: 50}, Ref{book: Exodus, chapter: 2}},
+			"Genesis 50-Exodus 2",
+		},
+		{
+			"Psalm 23",
+			Range{Ref{book: Psalm, chapter: 23}, Ref{book: Psalm, chapter: 23}},
+			"Psalm 23",
+		},
+	}
+
+	for _, c := range cases {
+		r, err := ParseRange(c.s)
+		if err != nil {
+			t.Errorf("ParseRange error: %q", err)
+			continue
+		}
+
+		if *r != c.r {
+			t.Errorf("parsed range: %+v, expected %+v", r, c.r)
+		}
+
+		if r.String() != c.out {
+			t.Errorf("(%v).String() -> %q, wanted %q", r, r.String(), c.out)
+		}
+	}
+
+	if _, err := ParseRange("John 3:16-2"); err == nil {
+		t.Errorf("expected an error for a backwards range")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	r := Ref{book: John, chapter: 3, verse: 16}
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"ref":"John 3:16","book":"John","usfm":"JHN","chapter":3,"verse":16}`
+	if string(data) != expected {
+		t.Errorf("got %s, wanted %s", data, expected)
+	}
+
+	var decoded Ref
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != r {
+		t.Errorf("decoded %+v, wanted %+v", decoded, r)
+	}
+}
+
+func TestVerses(t *testing.T) {
+	total := 0
+	for b := Genesis; b <= Revelation; b++ {
+		if len(numVerses[b]) != b.Chapters() {
+			t.Errorf("%v has verse counts for %d chapters, wanted %d", b, len(numVerses[b]), b.Chapters())
+		}
+		for c := 1; c <= b.Chapters(); c++ {
+			total += b.Verses(c)
+		}
+	}
+
+	if total != 31102 || TotalVerses() != 31102 {
+		t.Errorf("%d verses in the Bible, wanted 31102", total)
+	}
+
+	cases := []struct {
+		b       Book
+		chapter int
+		verses  int
+	}{
+		{Genesis, 1, 31},
+		{Psalm, 119, 176},
+		{Psalm, 117, 2},
+		{John3, 1, 14},
+		{Revelation, 22, 21},
+		{John, 22, 0},
+	}
+
+	for _, c := range cases {
+		if n := c.b.Verses(c.chapter); n != c.verses {
+			t.Errorf("(%v).Verses(%d) -> %d, wanted %d", c.b, c.chapter, n, c.verses)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	cases := []struct {
+		r   Ref
+		n   int
+		out string
+	}{
+		{Ref{book: John, chapter: 3, verse: 16}, 2, "John 3:18"},
+		{Ref{book: John, chapter: 3, verse: 16}, -5, "John 3:11"},
+		{Ref{book: John, chapter: 3, verse: 35}, 3, "John 4:2"},
+		{Ref{book: John, chapter: 4, verse: 2}, -3, "John 3:35"},
+		{Ref{book: John, chapter: 3}, -1, "John 2:25"},
+		{Ref{book: Genesis, chapter: 1, verse: 2}, -10, "Genesis 1:1"},
+		{Ref{book: Jude, chapter: 1, verse: 24}, 10, "Jude 1:25"},
+	}
+
+	for _, c := range cases {
+		if out := c.r.Add(c.n).String(); out != c.out {
+			t.Errorf("(%v).Add(%d) -> %v, wanted %v", c.r.String(), c.n, out, c.out)
+		}
+	}
+}
+
+func TestRangeContext(t *testing.T) {
+	cases := []struct {
+		s        string
+		n        int
+		context  string
+		chapters string
+	}{
+		{"John 3:16", 2, "John 3:14-18", "John 3"},
+		{"John 3:35-4:1", 2, "John 3:33-4:3", "John 3-4"},
+		{"Psalm 117", 1, "Psalm 116:19-118:1", "Psalm 117"},
+		{"Genesis 1:1", 3, "Genesis 1:1-4", "Genesis 1"},
+	}
+
+	for _, c := range cases {
+		r, err := ParseRange(c.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if out := r.Context(c.n).String(); out != c.context {
+			t.Errorf("(%v).Context(%d) -> %v, wanted %v", c.s, c.n, out, c.context)
+		}
+		if out := r.Chapters().String(); out != c.chapters {
+			t.Errorf("(%v).Chapters() -> %v, wanted %v", c.s, out, c.chapters)
+		}
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	r, _ := ParseRange("John 3:16-4:2")
+	cases := []struct {
+		r   Ref
+		out bool
+	}{
+		{Ref{book: John, chapter: 3, verse: 15}, false},
+		{Ref{book: John, chapter: 3, verse: 16}, true},
+		{Ref{book: John, chapter: 3, verse: 36}, true},
+		{Ref{book: John, chapter: 4, verse: 2}, true},
+		{Ref{book: John, chapter: 4, verse: 3}, false},
+		{Ref{book: Luke, chapter: 3, verse: 20}, false},
+	}
+
+	for _, c := range cases {
+		if out := r.Contains(&c.r); out != c.out {
+			t.Errorf("(%v).Contains(%v) -> %v, wanted %v", r, c.r.String(), out, c.out)
+		}
+	}
+
+	chapter, _ := ParseRange("Psalm 23")
+	if !chapter.Contains(&Ref{book: Psalm, chapter: 23, verse: 6}) {
+		t.Errorf("Psalm 23 doesn't contain its last verse")
+	}
+}
+
+func TestVerseAt(t *testing.T) {
+	cases := []struct {
+		n   int
+		out string
+	}{
+		{0, "Genesis 1:1"},
+		{30, "Genesis 1:31"},
+		{31, "Genesis 2:1"},
+		{1533, "Exodus 1:1"},
+		{31101, "Revelation 22:21"},
+	}
+
+	for _, c := range cases {
+		if r := VerseAt(c.n); r == nil || r.String() != c.out {
+			t.Errorf("VerseAt(%d) -> %v, wanted %v", c.n, r, c.out)
+		}
+		if r, _ := Parse(c.out); Index(r) != c.n {
+			t.Errorf("Index(%v) -> %d, wanted %d", c.out, Index(r), c.n)
+		}
+	}
+
+	if r := VerseAt(31102); r != nil {
+		t.Errorf("VerseAt(31102) -> %v, wanted nil", r)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	ot, _ := Testament("old")
+	nt, _ := Testament("NT")
+	if len(ot) != 39 || len(nt) != 27 {
+		t.Errorf("%d books in the Old Testament and %d in the New, wanted 39 and 27", len(ot), len(nt))
+	}
+
+	gospels, err := Group("Gospels")
+	if err != nil || len(gospels) != 4 || gospels[3] != John {
+		t.Errorf("Group(Gospels) -> %v, %v", gospels, err)
+	}
+
+	if _, err := Group("apocrypha"); err == nil {
+		t.Errorf("expected an error for an unknown group")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		in, style, out string
+	}{
+		{"John 3:16", "full", "John 3:16"},
+		{"John 3:16", "short", "John 3:16"},
+		{"John 3:16", "usfm", "JHN.3.16"},
+		{"1 Corinthians 13:4-7", "short", "1 Cor 13:4-7"},
+		{"1 Corinthians 13:4-7", "usfm", "1CO.13.4-1CO.13.7"},
+		{"Genesis 50-Exodus 2", "short", "Gen 50-Exod 2"},
+		{"Psalm 23", "usfm", "PSA.23"},
+		{"Romans 8:28", "", "Romans 8:28"},
+	}
+
+	for _, c := range cases {
+		r, err := ParseRange(c.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Format(c.style); got != c.out {
+			t.Errorf("(%v).Format(%q) -> %v, wanted %v", c.in, c.style, got, c.out)
+		}
+	}
+
+	if err := CheckStyle("USFM"); err != nil {
+		t.Error(err)
+	}
+	if err := CheckStyle("osis"); err == nil {
+		t.Errorf("expected an error for an unknown style")
+	}
+}
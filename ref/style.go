@@ -0,0 +1,85 @@
+package ref
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Styles lists the ways a reference can be written out: spelled out as in
+// "John 3:16", abbreviated as in "Rom 8:28" or "1 Cor 13:4", or as USFM
+// codes as in "JHN.3.16"
+var Styles = []string{"full", "short", "usfm"}
+
+// abbrevs are the short names of the books, indexed by Book, as the SBL
+// Handbook of Style gives them
+var abbrevs = [...]string{"",
+	"Gen", "Exod", "Lev", "Num", "Deut", "Josh", "Judg", "Ruth", "1 Sam", "2 Sam",
+	"1 Kgs", "2 Kgs", "1 Chr", "2 Chr", "Ezra", "Neh", "Esth", "Job", "Ps", "Prov",
+	"Eccl", "Song", "Isa", "Jer", "Lam", "Ezek", "Dan", "Hos", "Joel", "Amos",
+	"Obad", "Jonah", "Mic", "Nah", "Hab", "Zeph", "Hag", "Zech", "Mal",
+	"Matt", "Mark", "Luke", "John", "Acts", "Rom", "1 Cor", "2 Cor", "Gal", "Eph",
+	"Phil", "Col", "1 Thess", "2 Thess", "1 Tim", "2 Tim", "Titus", "Phlm", "Heb", "Jas",
+	"1 Pet", "2 Pet", "1 John", "2 John", "3 John", "Jude", "Rev",
+}
+
+// Abbrev returns the short name of the book, e.g. "Gen" or "1 Cor"
+func (b Book) Abbrev() string {
+	if int(b) <= 0 || int(b) >= len(abbrevs) {
+		return b.String()
+	}
+
+	return abbrevs[b]
+}
+
+// CheckStyle returns an error if style is not one of Styles
+func CheckStyle(style string) error {
+	for _, s := range Styles {
+		if strings.EqualFold(style, s) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unknown reference style %q, expected one of %q", style, Styles)
+}
+
+// Format writes the reference in the given style. Unknown styles are written
+// in full.
+func (r *Ref) Format(style string) string {
+	switch strings.ToLower(style) {
+	case "short":
+		return r.format(Book.Abbrev, " ", ":")
+	case "usfm":
+		return r.format(Book.USFM, ".", ".")
+	}
+
+	return r.String()
+}
+
+func (r *Ref) format(name func(Book) string, chapterSep, verseSep string) string {
+	s := name(r.book)
+	if r.chapter > 0 {
+		s += fmt.Sprintf("%s%d", chapterSep, r.chapter)
+	}
+	if r.verse > 0 {
+		s += fmt.Sprintf("%s%d", verseSep, r.verse)
+	}
+
+	return s
+}
+
+// Format writes the range in the given style. USFM ranges give both ends in
+// full, e.g. "JHN.3.16-JHN.3.18".
+func (r *Range) Format(style string) string {
+	switch strings.ToLower(style) {
+	case "short":
+		return r.format(func(r *Ref) string { return r.Format(style) })
+
+	case "usfm":
+		if r.Start == r.End {
+			return r.Start.Format(style)
+		}
+		return r.Start.Format(style) + "-" + r.End.Format(style)
+	}
+
+	return r.String()
+}
@@ -0,0 +1,162 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+)
+
+// block is a run of a passage that is laid out together: a heading, a
+// paragraph of prose or a stanza of poetry
+type block struct {
+	heading *passage.Heading
+	poetry  bool
+	verses  []passage.Verse
+}
+
+// blocks groups the verses of a passage into blocks, leaving out the headings
+// the options turn off
+func blocks(p *passage.Passage, opts passage.Options) []block {
+	var bs []block
+	for _, v := range p.Verses {
+		for i, h := range v.Headings {
+			if h.Level <= 1 && opts.Headings || h.Level > 1 && opts.Subheadings {
+				bs = append(bs, block{heading: &v.Headings[i]})
+			}
+		}
+
+		n := len(bs)
+		if n == 0 || bs[n-1].heading != nil || v.Paragraph || bs[n-1].poetry != v.Poetry {
+			bs = append(bs, block{poetry: v.Poetry})
+		}
+		bs[len(bs)-1].verses = append(bs[len(bs)-1].verses, v)
+	}
+
+	return bs
+}
+
+// verseNumber returns the number to print before a verse, which includes the
+// chapter at the start of a chapter
+func verseNumber(v passage.Verse) string {
+	if v.Ref.Verse() == 1 && v.Ref.Chapter() > 0 {
+		return fmt.Sprintf("%d:%d", v.Ref.Chapter(), v.Ref.Verse())
+	}
+
+	return fmt.Sprintf("%d", v.Ref.Verse())
+}
+
+// style decorates the parts of a passage. Each function is given text that
+// has already been escaped for the output format.
+type style struct {
+	verseNumber  func(string) string
+	heading      func(string) string
+	wordsOfJesus func(string) string
+	footnote     func(string) string
+
+	// focus marks the verses asked for in a passage widened for context
+	focus func(string) string
+
+	// highlight marks a verse the user has highlighted in a colour, or is
+	// nil if the format can't show highlights
+	highlight func(color, s string) string
+}
+
+func plain(s string) string {
+	return s
+}
+
+// plainStyle leaves text as it is, apart from putting asterisks around the
+// verses in focus
+var plainStyle = style{plain, plain, plain, plain, emphasis("*", "*"), nil}
+
+// emphasis returns a style function that puts text between open and close,
+// leaving any space at either end outside them
+func emphasis(open, close string) func(string) string {
+	return func(s string) string {
+		trimmed := strings.TrimSpace(s)
+		if trimmed == "" {
+			return s
+		}
+
+		i := strings.Index(s, trimmed)
+		return s[:i] + open + trimmed + close + s[i+len(trimmed):]
+	}
+}
+
+// eachLine applies f to each non-empty line of s, so that poetry can be
+// indented between them
+func eachLine(s string, f func(string) string) string {
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		if lines[i] != "" {
+			lines[i] = f(lines[i])
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// footnotes numbers the footnotes of a passage in the order they appear
+type footnotes struct {
+	notes []passage.Footnote
+	refs  []string
+}
+
+// verseText renders the text of a verse. Each run of words is passed through
+// escape, and then through the style's wordsOfJesus if Jesus is speaking. Each
+// footnote is replaced by the marker returned by note (if the options include
+// footnotes), and line breaks in poetry become lineBreak. Verses in focus go
+// through the style's focus and highlight before wordsOfJesus.
+func (f *footnotes) verseText(v passage.Verse, opts passage.Options, st style, escape func(string) string, note func(n int, fn passage.Footnote) string, lineBreak string) string {
+	// Split the text wherever a footnote goes or Jesus starts or stops
+	// speaking
+	cuts := map[int]bool{0: true, len(v.Text): true}
+	if opts.Footnotes {
+		for _, fn := range v.Footnotes {
+			cuts[fn.Offset] = true
+		}
+	}
+	for _, s := range v.WordsOfJesus {
+		cuts[s.Start], cuts[s.End] = true, true
+	}
+
+	offsets := make([]int, 0, len(cuts))
+	for o := range cuts {
+		offsets = append(offsets, o)
+	}
+	sort.Ints(offsets)
+
+	var out []string
+	notes := v.Footnotes
+	for i, o := range offsets {
+		for opts.Footnotes && len(notes) > 0 && notes[0].Offset == o {
+			f.notes = append(f.notes, notes[0])
+			f.refs = append(f.refs, fmt.Sprintf("%d:%d", v.Ref.Chapter(), v.Ref.Verse()))
+			out = append(out, note(len(f.notes), notes[0]))
+			notes = notes[1:]
+		}
+
+		if i+1 == len(offsets) {
+			break
+		}
+
+		text := escape(v.Text[o:offsets[i+1]])
+		if v.Focus {
+			text = eachLine(text, st.focus)
+		}
+		if v.Highlight != "" && st.highlight != nil {
+			text = eachLine(text, func(s string) string { return st.highlight(v.Highlight, s) })
+		}
+		for _, s := range v.WordsOfJesus {
+			if o >= s.Start && o < s.End {
+				text = eachLine(text, st.wordsOfJesus)
+				break
+			}
+		}
+		out = append(out, text)
+	}
+
+	return strings.Replace(strings.Join(out, ""), "\n", lineBreak, -1)
+}
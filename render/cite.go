@@ -0,0 +1,288 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+// CitationStyles lists the style guides Cite understands
+var CitationStyles = []string{"sbl", "chicago", "apa", "mla"}
+
+// CitationFormats lists the formats Cite can write
+var CitationFormats = []string{"text", "markdown", "html"}
+
+// Citation describes how Cite quotes a passage
+type Citation struct {
+	// Style is the style guide to follow, one of CitationStyles
+	Style string
+
+	// Block sets the passage apart as a block quotation rather than running
+	// it inline in quotation marks
+	Block bool
+
+	// Format is the output format, one of CitationFormats
+	Format string
+}
+
+// bibleEdition is what the style guides need to know about a translation
+type bibleEdition struct {
+	Name      string
+	Publisher string
+	Year      int
+}
+
+// editions holds the details of well-known translations, by abbreviation
+var editions = map[string]bibleEdition{
+	"ASV":  {"American Standard Version", "Thomas Nelson & Sons", 1901},
+	"BBE":  {"Bible in Basic English", "Cambridge University Press", 1965},
+	"CSB":  {"Christian Standard Bible", "Holman Bible Publishers", 2017},
+	"ESV":  {"English Standard Version", "Crossway", 2001},
+	"KJV":  {"King James Version", "", 1769},
+	"NASB": {"New American Standard Bible", "The Lockman Foundation", 2020},
+	"NIV":  {"New International Version", "Biblica", 2011},
+	"NKJV": {"New King James Version", "Thomas Nelson", 1982},
+	"NLT":  {"New Living Translation", "Tyndale House Publishers", 2015},
+	"NRSV": {"New Revised Standard Version", "National Council of Churches", 1989},
+	"WEB":  {"World English Bible", "", 2000},
+	"YLT":  {"Young's Literal Translation", "", 1898},
+}
+
+// Abbreviations of the books, indexed by ref.Book, as each style guide gives
+// them. APA spells the books out.
+var (
+	sblBooks = [...]string{"",
+		"Gen", "Exod", "Lev", "Num", "Deut", "Josh", "Judg", "Ruth", "1 Sam", "2 Sam",
+		"1 Kgs", "2 Kgs", "1 Chr", "2 Chr", "Ezra", "Neh", "Esth", "Job", "Ps", "Prov",
+		"Eccl", "Song", "Isa", "Jer", "Lam", "Ezek", "Dan", "Hos", "Joel", "Amos",
+		"Obad", "Jonah", "Mic", "Nah", "Hab", "Zeph", "Hag", "Zech", "Mal",
+		"Matt", "Mark", "Luke", "John", "Acts", "Rom", "1 Cor", "2 Cor", "Gal", "Eph",
+		"Phil", "Col", "1 Thess", "2 Thess", "1 Tim", "2 Tim", "Titus", "Phlm", "Heb", "Jas",
+		"1 Pet", "2 Pet", "1 John", "2 John", "3 John", "Jude", "Rev",
+	}
+
+	chicagoBooks = [...]string{"",
+		"Gen.", "Exod.", "Lev.", "Num.", "Deut.", "Josh.", "Judg.", "Ruth", "1 Sam.", "2 Sam.",
+		"1 Kings", "2 Kings", "1 Chron.", "2 Chron.", "Ezra", "Neh.", "Esther", "Job", "Ps.", "Prov.",
+		"Eccles.", "Song of Sol.", "Isa.", "Jer.", "Lam.", "Ezek.", "Dan.", "Hosea", "Joel", "Amos",
+		"Obad.", "Jon.", "Mic.", "Nah.", "Hab.", "Zeph.", "Hag.", "Zech.", "Mal.",
+		"Matt.", "Mark", "Luke", "John", "Acts", "Rom.", "1 Cor.", "2 Cor.", "Gal.", "Eph.",
+		"Phil.", "Col.", "1 Thess.", "2 Thess.", "1 Tim.", "2 Tim.", "Titus", "Philem.", "Heb.", "James",
+		"1 Pet.", "2 Pet.", "1 John", "2 John", "3 John", "Jude", "Rev.",
+	}
+
+	mlaBooks = [...]string{"",
+		"Gen.", "Exod.", "Lev.", "Num.", "Deut.", "Josh.", "Judg.", "Ruth", "1 Sam.", "2 Sam.",
+		"1 Kgs.", "2 Kgs.", "1 Chron.", "2 Chron.", "Ezra", "Neh.", "Esth.", "Job", "Ps.", "Prov.",
+		"Eccles.", "Song of Sol.", "Isa.", "Jer.", "Lam.", "Ezek.", "Dan.", "Hos.", "Joel", "Amos",
+		"Obad.", "Jon.", "Mic.", "Nah.", "Hab.", "Zeph.", "Hag.", "Zech.", "Mal.",
+		"Matt.", "Mark", "Luke", "John", "Acts", "Rom.", "1 Cor.", "2 Cor.", "Gal.", "Eph.",
+		"Phil.", "Col.", "1 Thess.", "2 Thess.", "1 Tim.", "2 Tim.", "Tit.", "Philem.", "Heb.", "Jas.",
+		"1 Pet.", "2 Pet.", "1 John", "2 John", "3 John", "Jude", "Rev.",
+	}
+)
+
+// Cite writes a passage as a quotation followed by its citation in the
+// given style. APA and MLA also get an entry for the reference list, and the
+// translation's copyright notice comes last.
+func Cite(w io.Writer, p *passage.Passage, c Citation) error {
+	paren, err := citation(p, c.Style)
+	if err != nil {
+		return err
+	}
+
+	var escape, italic func(string) string
+	switch c.Format {
+	case "", "text":
+		escape, italic = plain, plain
+	case "markdown", "md":
+		escape, italic = markdownEscaper.Replace, emphasis("*", "*")
+	case "html":
+		escape, italic = html.EscapeString, emphasis("<i>", "</i>")
+	default:
+		return fmt.Errorf("Unknown format %q, expected one of %q", c.Format, CitationFormats)
+	}
+
+	lines := quoteLines(p)
+	for i := range lines {
+		lines[i] = escape(lines[i])
+	}
+	paren = escape(paren)
+
+	var paras []string
+	switch {
+	case !c.Block && c.Format == "html":
+		paras = append(paras, "<p><q>"+inlineQuote(lines)+"</q> ("+paren+").</p>")
+
+	case !c.Block:
+		paras = append(paras, "“"+inlineQuote(lines)+"” ("+paren+").")
+
+	case c.Format == "html":
+		paras = append(paras, "<blockquote>\n<p>"+strings.Join(lines, "<br>\n")+"</p>\n<footer><cite>"+paren+"</cite></footer>\n</blockquote>")
+
+	case c.Format == "markdown" || c.Format == "md":
+		lines[len(lines)-1] += " (" + paren + ")"
+		paras = append(paras, "> "+strings.Join(lines, "  \n> "))
+
+	default:
+		lines[len(lines)-1] += " (" + paren + ")"
+		paras = append(paras, poetryIndent+strings.Join(lines, "\n"+poetryIndent))
+	}
+
+	if entry := referenceEntry(p, c.Style, italic); entry != "" {
+		paras = append(paras, entry)
+	}
+	if p.Copyright != "" {
+		paras = append(paras, escape(p.Copyright))
+	}
+
+	if c.Format == "html" {
+		for i := 1; i < len(paras); i++ {
+			paras[i] = "<p>" + paras[i] + "</p>"
+		}
+		_, err = fmt.Fprintln(w, strings.Join(paras, "\n"))
+	} else {
+		_, err = fmt.Fprintln(w, strings.Join(paras, "\n\n"))
+	}
+
+	return err
+}
+
+// quoteLines returns the text of the passage as lines, one per line of
+// poetry, with prose verses run together
+func quoteLines(p *passage.Passage) []string {
+	var lines []string
+	for i, v := range p.Verses {
+		for j, line := range strings.Split(v.Text, "\n") {
+			if i > 0 && j == 0 && !v.Poetry && !p.Verses[i-1].Poetry {
+				lines[len(lines)-1] += " " + line
+			} else {
+				lines = append(lines, line)
+			}
+		}
+	}
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+
+	return lines
+}
+
+// inlineQuote runs lines of poetry together with slashes, and leaves off the
+// closing punctuation, which moves after the citation
+func inlineQuote(lines []string) string {
+	return strings.TrimRight(strings.Join(lines, " / "), ".,;:")
+}
+
+// citation returns the parenthetical citation of a passage, without the
+// parentheses
+func citation(p *passage.Passage, style string) (string, error) {
+	abbrev := strings.ToUpper(p.Translation)
+	edition, known := editions[abbrev]
+
+	switch strings.ToLower(style) {
+	case "", "sbl":
+		return citeRange(p.Range, sblBooks[:], ":") + " " + abbrev, nil
+
+	case "chicago":
+		return citeRange(p.Range, chicagoBooks[:], ":") + ", " + abbrev, nil
+
+	case "apa":
+		r := citeRange(p.Range, nil, ":")
+		if !known {
+			return abbrev + ", " + r, nil
+		}
+		return fmt.Sprintf("%s, %d, %s", apaTitle(edition), edition.Year, r), nil
+
+	case "mla":
+		r := citeRange(p.Range, mlaBooks[:], ".")
+		if !known {
+			return abbrev + ", " + r, nil
+		}
+		return edition.Name + ", " + r, nil
+	}
+
+	return "", fmt.Errorf("Unknown citation style %q, expected one of %q", style, CitationStyles)
+}
+
+// referenceEntry returns the entry for the translation in an APA reference
+// list or MLA list of works cited, or "" if the style doesn't list Bibles or
+// the translation isn't known
+func referenceEntry(p *passage.Passage, style string, italic func(string) string) string {
+	edition, ok := editions[strings.ToUpper(p.Translation)]
+	if !ok {
+		return ""
+	}
+
+	switch strings.ToLower(style) {
+	case "apa":
+		entry := fmt.Sprintf("%s. (%d).", italic(apaTitle(edition)), edition.Year)
+		if edition.Publisher != "" {
+			entry += " " + edition.Publisher + "."
+		}
+		return entry
+
+	case "mla":
+		entry := italic("The Bible: "+edition.Name) + "."
+		if edition.Publisher != "" {
+			entry += " " + edition.Publisher + ","
+		}
+		return fmt.Sprintf("%s %d.", entry, edition.Year)
+	}
+
+	return ""
+}
+
+// apaTitle is the title of a translation as APA gives it, e.g. "English
+// Standard Version Bible", or "King James Bible" for the Authorized Version
+func apaTitle(e bibleEdition) string {
+	if e.Name == editions["KJV"].Name {
+		return "King James Bible"
+	}
+	if strings.Contains(e.Name, "Bible") {
+		return e.Name
+	}
+
+	return e.Name + " Bible"
+}
+
+// citeRange writes out a range with the given book abbreviations, or the full
+// names if there are none, and with sep between chapter and verse
+func citeRange(r ref.Range, books []string, sep string) string {
+	book := func(b ref.Book) string {
+		if books == nil {
+			return b.String()
+		}
+		return books[b]
+	}
+
+	chapterVerse := func(x ref.Ref) string {
+		if x.Verse() > 0 {
+			return fmt.Sprintf("%d%s%d", x.Chapter(), sep, x.Verse())
+		}
+		return fmt.Sprintf("%d", x.Chapter())
+	}
+
+	start, end := r.Start, r.End
+	s := book(start.Book())
+	if start.Chapter() > 0 {
+		s += " " + chapterVerse(start)
+	}
+
+	switch {
+	case start == end:
+		return s
+	case start.Book() != end.Book():
+		return s + "–" + book(end.Book()) + " " + chapterVerse(end)
+	case start.Chapter() == end.Chapter() && start.Verse() > 0 && end.Verse() > 0:
+		return fmt.Sprintf("%s–%d", s, end.Verse())
+	case start.Verse() == 0 && end.Verse() == 0:
+		return fmt.Sprintf("%s–%d", s, end.Chapter())
+	}
+
+	return s + "–" + chapterVerse(end)
+}
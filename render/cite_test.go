@@ -0,0 +1,110 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+func TestCite(t *testing.T) {
+	john, _ := ref.ParseRange("John 3:16")
+	p := &passage.Passage{
+		Range:       *john,
+		Translation: "ESV",
+		Copyright:   "Copyright Crossway",
+		Verses: []passage.Verse{
+			{Ref: *ref.New(ref.John, 3, 16), Text: "For God so loved the world, that he gave his only Son."},
+		},
+	}
+
+	cases := []struct {
+		c   Citation
+		out string
+	}{
+		{
+			Citation{Style: "sbl"},
+			"“For God so loved the world, that he gave his only Son” (John 3:16 ESV).\n\nCopyright Crossway\n",
+		},
+		{
+			Citation{Style: "chicago"},
+			"“For God so loved the world, that he gave his only Son” (John 3:16, ESV).\n\nCopyright Crossway\n",
+		},
+		{
+			Citation{Style: "apa"},
+			"“For God so loved the world, that he gave his only Son” (English Standard Version Bible, 2001, John 3:16).\n\n" +
+				"English Standard Version Bible. (2001). Crossway.\n\nCopyright Crossway\n",
+		},
+		{
+			Citation{Style: "mla", Format: "markdown"},
+			"“For God so loved the world, that he gave his only Son” (English Standard Version, John 3.16).\n\n" +
+				"*The Bible: English Standard Version*. Crossway, 2001.\n\nCopyright Crossway\n",
+		},
+		{
+			Citation{Style: "sbl", Format: "html"},
+			"<p><q>For God so loved the world, that he gave his only Son</q> (John 3:16 ESV).</p>\n<p>Copyright Crossway</p>\n",
+		},
+		{
+			Citation{Style: "sbl", Block: true},
+			"    For God so loved the world, that he gave his only Son. (John 3:16 ESV)\n\nCopyright Crossway\n",
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewBuffer(nil)
+		if err := Cite(buf, p, c.c); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != c.out {
+			t.Errorf("Cite with %+v:\n%q, wanted\n%q", c.c, buf.String(), c.out)
+		}
+	}
+
+	if err := Cite(bytes.NewBuffer(nil), p, Citation{Style: "turabian"}); err == nil {
+		t.Errorf("expected an error for an unknown style")
+	}
+}
+
+func TestCitePoetry(t *testing.T) {
+	psalm, _ := ref.ParseRange("Psalm 23:1-2")
+	p := &passage.Passage{
+		Range:       *psalm,
+		Translation: "KJV",
+		Verses: []passage.Verse{
+			{Ref: *ref.New(ref.Psalm, 23, 1), Text: "The LORD is my shepherd;\nI shall not want.", Poetry: true},
+			{Ref: *ref.New(ref.Psalm, 23, 2), Text: "He maketh me to lie down in green pastures:", Poetry: true},
+		},
+	}
+
+	cases := []struct {
+		c   Citation
+		out string
+	}{
+		{
+			Citation{Style: "sbl"},
+			"“The LORD is my shepherd; / I shall not want. / He maketh me to lie down in green pastures” (Ps 23:1–2 KJV).\n",
+		},
+		{
+			Citation{Style: "chicago", Block: true, Format: "markdown"},
+			"> The LORD is my shepherd;  \n> I shall not want.  \n> He maketh me to lie down in green pastures: (Ps. 23:1–2, KJV)\n",
+		},
+		{
+			Citation{Style: "mla", Block: true, Format: "html"},
+			"<blockquote>\n<p>The LORD is my shepherd;<br>\nI shall not want.<br>\nHe maketh me to lie down in green pastures:</p>\n" +
+				"<footer><cite>King James Version, Ps. 23.1–2</cite></footer>\n</blockquote>\n<p><i>The Bible: King James Version</i>. 1769.</p>\n",
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewBuffer(nil)
+		if err := Cite(buf, p, c.c); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != c.out {
+			t.Errorf("Cite with %+v:\n%q, wanted\n%q", c.c, buf.String(), c.out)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+)
+
+// Formats lists the output formats Write understands
+var Formats = []string{"text", "json", "markdown", "html", "latex"}
+
+// Write writes a passage in the named format
+func Write(w io.Writer, format string, p *passage.Passage, opts passage.Options) error {
+	switch format {
+	case "", "text":
+		return Text(w, p, opts)
+	case "json":
+		return JSON(w, p)
+	case "markdown", "md":
+		return Markdown(w, p, opts)
+	case "html":
+		return HTML(w, p, opts)
+	case "latex", "tex":
+		return LaTeX(w, p, opts)
+	}
+
+	return fmt.Errorf("Unknown format %q, expected one of %q", format, Formats)
+}
+
+// JSON writes the passage model as a single line of JSON. Every verse carries
+// its full reference, and headings, paragraph breaks, poetry and footnotes are
+// included as the provider supplied them.
+func JSON(w io.Writer, p *passage.Passage) error {
+	return json.NewEncoder(w).Encode(p)
+}
+
+// Separator returns the text to put between passages written one after
+// another in the named format
+func Separator(format string) string {
+	switch format {
+	case "markdown", "md":
+		return "\n---\n\n"
+	case "html":
+		return "<hr>\n"
+	case "latex", "tex":
+		return "\n\\bigskip\\hrule\\bigskip\n\n"
+	case "json":
+		return ""
+	}
+
+	return "\n" + strings.Repeat("-", 40) + "\n\n"
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dtjm/bible/passage"
+)
+
+func TestFormats(t *testing.T) {
+	cases := []struct {
+		format, out string
+	}{
+		{"markdown", `# Psalm 1:1-2 (ESV)
+
+## Book One
+
+### The Way of the Righteous
+
+<sup>1:1</sup> Blessed is the man[^1]  
+who walks not in the counsel of the wicked  
+<sup>2</sup> but his delight is in the law of the LORD
+
+*Copyright Crossway*
+
+[^1]: 1:1 Or \*the man\*
+`},
+		{"html", `<h1>Psalm 1:1-2 (ESV)</h1>
+<h2>Book One</h2>
+<h3>The Way of the Righteous</h3>
+<p class="poetry"><sup class="verse">1:1</sup> Blessed is the man<sup class="footnote"><a href="#fn1" id="fnref1">1</a></sup><br>
+who walks not in the counsel of the wicked<br>
+<sup class="verse">2</sup> but his delight is in the law of the LORD</p>
+<ol class="footnotes">
+<li id="fn1">1:1 Or *the man* <a href="#fnref1">↩</a></li>
+</ol>
+<p class="copyright">Copyright Crossway</p>
+`},
+		{"latex", `\section*{Psalm 1:1-2 (ESV)}
+
+\subsection*{Book One}
+
+\subsubsection*{The Way of the Righteous}
+
+\begin{verse}
+\textsuperscript{1:1}Blessed is the man\footnote{Or *the man*}\\
+who walks not in the counsel of the wicked\\
+\textsuperscript{2}but his delight is in the law of the LORD
+\end{verse}
+
+{\small Copyright Crossway}
+`},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewBuffer(nil)
+		if err := Write(buf, c.format, structuredPassage, structuredOptions); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != c.out {
+			t.Errorf("%s: got:\n%s\nwanted:\n%s", c.format, buf.String(), c.out)
+		}
+	}
+
+	if err := Write(bytes.NewBuffer(nil), "pdf", structuredPassage, structuredOptions); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := Write(buf, "json", structuredPassage, structuredOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Verses []struct {
+			Ref struct {
+				Ref     string `json:"ref"`
+				Chapter int    `json:"chapter"`
+				Verse   int    `json:"verse"`
+			} `json:"ref"`
+			Footnotes []passage.Footnote `json:"footnotes"`
+		} `json:"verses"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decoded.Verses) != 2 || decoded.Verses[1].Ref.Ref != "Psalm 1:2" ||
+		decoded.Verses[1].Ref.Verse != 2 || len(decoded.Verses[0].Footnotes) != 1 {
+		t.Errorf("got %s", buf.String())
+	}
+}
+
+func TestFocus(t *testing.T) {
+	p := *structuredPassage
+	p.Verses = append([]passage.Verse(nil), p.Verses...)
+	p.Verses[1].Focus = true
+
+	cases := []struct {
+		format, focus string
+	}{
+		{"text", "*but his delight is in the law of the LORD*"},
+		{"markdown", "**but his delight is in the law of the LORD**"},
+		{"html", "<mark>but his delight is in the law of the LORD</mark>"},
+		{"latex", `\textbf{but his delight is in the law of the LORD}`},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewBuffer(nil)
+		if err := Write(buf, c.format, &p, structuredOptions); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(buf.String(), c.focus) {
+			t.Errorf("%s with verse 2 in focus:\n%s\nwanted it to contain %q", c.format, buf.String(), c.focus)
+		}
+	}
+
+	// Only the verse in focus is marked
+	buf := bytes.NewBuffer(nil)
+	Write(buf, "markdown", &p, structuredOptions)
+	if strings.Count(buf.String(), "**") != 2 {
+		t.Errorf("markdown marks more than verse 2:\n%s", buf.String())
+	}
+}
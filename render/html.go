@@ -0,0 +1,70 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+)
+
+// htmlStyle puts the words of Jesus and the user's highlights in spans so they
+// can be coloured, and highlights the verses in focus
+var htmlStyle = style{plain, plain, func(s string) string {
+	return `<span class="words-of-jesus">` + s + "</span>"
+}, plain, emphasis("<mark>", "</mark>"), func(color, s string) string {
+	return `<span class="highlight-` + color + `">` + s + "</span>"
+}}
+
+// HTML writes a passage as a fragment of HTML. Verse numbers, poetry and
+// footnotes carry classes so they can be styled.
+func HTML(w io.Writer, p *passage.Passage, opts passage.Options) error {
+	var lines []string
+	if opts.References {
+		lines = append(lines, "<h1>"+html.EscapeString(reference(p))+"</h1>")
+	}
+
+	var notes footnotes
+	marker := func(n int, _ passage.Footnote) string {
+		return fmt.Sprintf(`<sup class="footnote"><a href="#fn%d" id="fnref%d">%d</a></sup>`, n, n, n)
+	}
+
+	for _, b := range blocks(p, opts) {
+		if b.heading != nil {
+			level := b.heading.Level + 1
+			lines = append(lines, fmt.Sprintf("<h%d>%s</h%d>", level, html.EscapeString(b.heading.Text), level))
+			continue
+		}
+
+		texts := make([]string, len(b.verses))
+		for i, v := range b.verses {
+			texts[i] = notes.verseText(v, opts, htmlStyle, html.EscapeString, marker, "<br>\n")
+			if opts.VerseNumbers {
+				texts[i] = fmt.Sprintf(`<sup class="verse">%s</sup> %s`, verseNumber(v), texts[i])
+			}
+		}
+
+		if b.poetry {
+			lines = append(lines, `<p class="poetry">`+strings.Join(texts, "<br>\n")+"</p>")
+		} else {
+			lines = append(lines, "<p>"+strings.Join(texts, " ")+"</p>")
+		}
+	}
+
+	if len(notes.notes) > 0 {
+		lines = append(lines, `<ol class="footnotes">`)
+		for i, fn := range notes.notes {
+			lines = append(lines, fmt.Sprintf(`<li id="fn%d">%s %s <a href="#fnref%d">↩</a></li>`,
+				i+1, notes.refs[i], html.EscapeString(fn.Text), i+1))
+		}
+		lines = append(lines, "</ol>")
+	}
+
+	if opts.Copyright && p.Copyright != "" {
+		lines = append(lines, `<p class="copyright">`+html.EscapeString(p.Copyright)+"</p>")
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
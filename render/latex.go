@@ -0,0 +1,64 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+)
+
+var latexEscaper = strings.NewReplacer(
+	"\\", "\\textbackslash{}", "{", "\\{", "}", "\\}", "$", "\\$", "&", "\\&",
+	"#", "\\#", "^", "\\textasciicircum{}", "_", "\\_", "%", "\\%", "~", "\\textasciitilde{}",
+)
+
+// latexStyle puts the verses in focus in bold
+var latexStyle = style{plain, plain, plain, plain, emphasis(`\textbf{`, "}"), nil}
+
+// LaTeX writes a passage as a LaTeX fragment for inclusion in a document.
+// Footnotes become \footnote commands where they occur, and poetry is set in a
+// verse environment.
+func LaTeX(w io.Writer, p *passage.Passage, opts passage.Options) error {
+	var paras []string
+	if opts.References {
+		paras = append(paras, `\section*{`+latexEscaper.Replace(reference(p))+"}")
+	}
+
+	var notes footnotes
+	marker := func(_ int, fn passage.Footnote) string {
+		return `\footnote{` + latexEscaper.Replace(fn.Text) + "}"
+	}
+
+	for _, b := range blocks(p, opts) {
+		if b.heading != nil {
+			cmd := `\subsection*`
+			if b.heading.Level > 1 {
+				cmd = `\subsubsection*`
+			}
+			paras = append(paras, cmd+"{"+latexEscaper.Replace(b.heading.Text)+"}")
+			continue
+		}
+
+		texts := make([]string, len(b.verses))
+		for i, v := range b.verses {
+			texts[i] = notes.verseText(v, opts, latexStyle, latexEscaper.Replace, marker, "\\\\\n")
+			if opts.VerseNumbers {
+				texts[i] = fmt.Sprintf(`\textsuperscript{%s}%s`, verseNumber(v), texts[i])
+			}
+		}
+
+		if b.poetry {
+			paras = append(paras, "\\begin{verse}\n"+strings.Join(texts, "\\\\\n")+"\n\\end{verse}")
+		} else {
+			paras = append(paras, wrapLines(strings.Join(texts, " "), opts.LineLength))
+		}
+	}
+
+	if opts.Copyright && p.Copyright != "" {
+		paras = append(paras, `{\small `+latexEscaper.Replace(p.Copyright)+"}")
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(paras, "\n\n"))
+	return err
+}
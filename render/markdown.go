@@ -0,0 +1,68 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]",
+	"<", "&lt;", ">", "&gt;", "`", "\\`",
+)
+
+// markdownStyle puts the verses in focus in bold
+var markdownStyle = style{plain, plain, plain, plain, emphasis("**", "**"), nil}
+
+// Markdown writes a passage as Markdown, with footnotes in the
+// "[^1]: Note" form understood by GitHub, Pandoc and most other renderers
+func Markdown(w io.Writer, p *passage.Passage, opts passage.Options) error {
+	var paras []string
+	if opts.References {
+		paras = append(paras, "# "+markdownEscaper.Replace(reference(p)))
+	}
+
+	var notes footnotes
+	marker := func(n int, _ passage.Footnote) string {
+		return fmt.Sprintf("[^%d]", n)
+	}
+
+	for _, b := range blocks(p, opts) {
+		if b.heading != nil {
+			paras = append(paras, strings.Repeat("#", b.heading.Level+1)+" "+markdownEscaper.Replace(b.heading.Text))
+			continue
+		}
+
+		// Two trailing spaces make a hard line break between lines of poetry
+		texts := make([]string, len(b.verses))
+		for i, v := range b.verses {
+			texts[i] = notes.verseText(v, opts, markdownStyle, markdownEscaper.Replace, marker, "  \n")
+			if opts.VerseNumbers {
+				texts[i] = fmt.Sprintf("<sup>%s</sup> %s", verseNumber(v), texts[i])
+			}
+		}
+
+		if b.poetry {
+			paras = append(paras, strings.Join(texts, "  \n"))
+		} else {
+			paras = append(paras, wrapLines(strings.Join(texts, " "), opts.LineLength))
+		}
+	}
+
+	if opts.Copyright && p.Copyright != "" {
+		paras = append(paras, "*"+markdownEscaper.Replace(p.Copyright)+"*")
+	}
+
+	if len(notes.notes) > 0 {
+		lines := make([]string, len(notes.notes))
+		for i, fn := range notes.notes {
+			lines[i] = fmt.Sprintf("[^%d]: %s %s", i+1, notes.refs[i], markdownEscaper.Replace(fn.Text))
+		}
+		paras = append(paras, strings.Join(lines, "\n"))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(paras, "\n\n"))
+	return err
+}
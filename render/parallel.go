@@ -0,0 +1,148 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+)
+
+const (
+	// MinColumnWidth is the narrowest a column may be before Parallel falls
+	// back to interleaving the translations verse by verse
+	MinColumnWidth = 30
+
+	gutter = "  "
+
+	// partialMark follows the numbers of verses missing from some of the
+	// translations
+	partialMark = "*"
+
+	// focusMark comes before the numbers of the verses asked for in a passage
+	// widened for context
+	focusMark = ">"
+)
+
+// Parallel writes the passages side by side in columns that fit in width, or
+// interleaved verse by verse if the columns would be too narrow
+func Parallel(w io.Writer, passages []*passage.Passage, width int) error {
+	rows := passage.Align(passages...)
+
+	n := len(passages)
+	colWidth := (width - len(gutter)*(n-1)) / n
+	var err error
+	if colWidth < MinColumnWidth {
+		err = interleaved(w, passages, rows, width)
+	} else {
+		err = columns(w, passages, rows, colWidth)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		if r.Partial {
+			_, err = fmt.Fprintf(w, "\n%s Not present in every translation\n", partialMark)
+			break
+		}
+	}
+
+	return err
+}
+
+// verseLabel returns the verse number of a row, marked if the verse is
+// missing from some translations or is in focus
+func verseLabel(r passage.Row) string {
+	label := strconv.Itoa(r.Ref.Verse())
+	if r.Ref.Chapter() > 0 && r.Ref.Verse() == 1 {
+		label = fmt.Sprintf("%d:%d", r.Ref.Chapter(), r.Ref.Verse())
+	}
+
+	if r.Partial {
+		label += partialMark
+	}
+	if r.Focus {
+		label = focusMark + label
+	}
+
+	return label
+}
+
+func columns(w io.Writer, passages []*passage.Passage, rows []passage.Row, colWidth int) error {
+	header := make([]string, len(passages))
+	for i, p := range passages {
+		header[i] = pad(p.Translation, colWidth)
+	}
+	if _, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(header, gutter), " ")); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		label := verseLabel(r)
+
+		cells := make([][]string, len(r.Texts))
+		height := 0
+		for i, text := range r.Texts {
+			if text != "" {
+				cells[i] = wrap(label+" "+text, colWidth)
+			}
+			if len(cells[i]) > height {
+				height = len(cells[i])
+			}
+		}
+
+		for l := 0; l < height; l++ {
+			line := make([]string, len(cells))
+			for i, cell := range cells {
+				if l < len(cell) {
+					line[i] = pad(cell[l], colWidth)
+				} else {
+					line[i] = pad("", colWidth)
+				}
+			}
+
+			if _, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(line, gutter), " ")); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func interleaved(w io.Writer, passages []*passage.Passage, rows []passage.Row, width int) error {
+	nameWidth := 0
+	for _, p := range passages {
+		if len(p.Translation) > nameWidth {
+			nameWidth = len(p.Translation)
+		}
+	}
+	indent := strings.Repeat(" ", nameWidth+len(gutter))
+
+	for _, r := range rows {
+		if _, err := fmt.Fprintln(w, verseLabel(r)); err != nil {
+			return err
+		}
+
+		for i, text := range r.Texts {
+			if text == "" {
+				continue
+			}
+
+			for l, line := range wrap(text, width-len(indent)) {
+				prefix := indent
+				if l == 0 {
+					prefix = pad(passages[i].Translation, nameWidth) + gutter
+				}
+
+				if _, err := fmt.Fprintln(w, prefix+line); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
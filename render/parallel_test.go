@@ -0,0 +1,70 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+var parallelPassages = []*passage.Passage{
+	{Translation: "ESV", Verses: []passage.Verse{
+		{Ref: *ref.New(ref.John, 11, 35), Text: "Jesus wept."},
+		{Ref: *ref.New(ref.John, 11, 36), Text: "So the Jews said, \"See how he loved him!\""},
+	}},
+	{Translation: "KJV", Verses: []passage.Verse{
+		{Ref: *ref.New(ref.John, 11, 35), Text: "Jesus wept."},
+	}},
+}
+
+func TestParallelColumns(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := Parallel(buf, parallelPassages, 70); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `ESV                                 KJV
+35 Jesus wept.                      35 Jesus wept.
+36* So the Jews said, "See how he
+loved him!"
+
+* Not present in every translation
+`
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nwanted:\n%s", buf.String(), expected)
+	}
+}
+
+func TestParallelInterleaved(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := Parallel(buf, parallelPassages, 40); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `35
+ESV  Jesus wept.
+KJV  Jesus wept.
+36*
+ESV  So the Jews said, "See how he loved
+     him!"
+
+* Not present in every translation
+`
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nwanted:\n%s", buf.String(), expected)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	lines := wrap("In the beginning was the Word", 10)
+	expected := []string{"In the", "beginning", "was the", "Word"}
+	if len(lines) != len(expected) {
+		t.Fatalf("got %q, wanted %q", lines, expected)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("got %q, wanted %q", lines, expected)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/fatih/color"
+)
+
+// poetryIndent is the indentation of lines of poetry in plain text
+const poetryIndent = "    "
+
+// colorStyle highlights verse numbers and headings, and prints the words of
+// Jesus in red. Verses in focus are underlined.
+var colorStyle = style{
+	verseNumber:  colorFunc(color.New(color.FgYellow)),
+	heading:      colorFunc(color.New(color.Bold)),
+	wordsOfJesus: colorFunc(color.New(color.FgRed)),
+	footnote:     colorFunc(color.New(color.Faint)),
+	focus:        colorFunc(color.New(color.Underline)),
+	highlight: func(c, s string) string {
+		if h, ok := highlights[c]; ok {
+			return h(s)
+		}
+		return s
+	},
+}
+
+// highlights are the backgrounds verses highlighted in each colour are
+// printed on
+var highlights = map[string]func(string) string{
+	"yellow": colorFunc(color.New(color.BgYellow, color.FgBlack)),
+	"green":  colorFunc(color.New(color.BgGreen, color.FgBlack)),
+	"blue":   colorFunc(color.New(color.BgBlue, color.FgWhite)),
+	"pink":   colorFunc(color.New(color.BgMagenta, color.FgWhite)),
+	"cyan":   colorFunc(color.New(color.BgCyan, color.FgBlack)),
+	"red":    colorFunc(color.New(color.BgRed, color.FgWhite)),
+}
+
+// noteMarker marks where the user's notes on a passage end
+const noteMarker = "✎"
+
+func colorFunc(c *color.Color) func(string) string {
+	f := c.SprintFunc()
+	return func(s string) string {
+		return f(s)
+	}
+}
+
+// Text writes a passage as plain text, with footnotes and the user's notes
+// listed at the end
+func Text(w io.Writer, p *passage.Passage, opts passage.Options) error {
+	return text(w, p, opts, plainStyle)
+}
+
+// Terminal writes a passage as text for reading in a terminal. Unless the
+// options give a line length, it is wrapped to width. With color, verse
+// numbers and headings are highlighted and the words of Jesus are in red.
+func Terminal(w io.Writer, p *passage.Passage, opts passage.Options, width int, color bool) error {
+	if opts.LineLength == 0 {
+		opts.LineLength = width
+	}
+
+	if color {
+		return text(w, p, opts, colorStyle)
+	}
+
+	return text(w, p, opts, plainStyle)
+}
+
+func text(w io.Writer, p *passage.Passage, opts passage.Options, st style) error {
+	var paras []string
+	if opts.References {
+		paras = append(paras, st.heading(reference(p)))
+	}
+
+	var notes footnotes
+	marker := func(n int, _ passage.Footnote) string {
+		return st.footnote(fmt.Sprintf("(%d)", n))
+	}
+	var userNotes []passage.Note
+
+	for _, b := range blocks(p, opts) {
+		if b.heading != nil {
+			paras = append(paras, st.heading(b.heading.Text))
+			continue
+		}
+
+		texts := make([]string, len(b.verses))
+		for i, v := range b.verses {
+			texts[i] = notes.verseText(v, opts, st, plain, marker, "\n")
+			for _, n := range v.Notes {
+				texts[i] += st.footnote(fmt.Sprintf("%s%d", noteMarker, n.ID))
+				userNotes = append(userNotes, n)
+			}
+			if opts.VerseNumbers {
+				texts[i] = st.verseNumber("["+verseNumber(v)+"]") + " " + texts[i]
+			}
+		}
+
+		if b.poetry {
+			text := strings.Replace(strings.Join(texts, "\n"), "\n", "\n"+poetryIndent, -1)
+			paras = append(paras, wrapLines(poetryIndent+text, opts.LineLength))
+		} else {
+			paras = append(paras, wrapLines(strings.Join(texts, " "), opts.LineLength))
+		}
+	}
+
+	if len(notes.notes) > 0 {
+		paras = append(paras, "Footnotes")
+		lines := make([]string, len(notes.notes))
+		for i, fn := range notes.notes {
+			lines[i] = wrapLines(fmt.Sprintf("(%d) %s %s", i+1, notes.refs[i], fn.Text), opts.LineLength)
+		}
+		paras = append(paras, strings.Join(lines, "\n"))
+	}
+
+	if len(userNotes) > 0 {
+		paras = append(paras, "Notes")
+		lines := make([]string, len(userNotes))
+		for i, n := range userNotes {
+			lines[i] = wrapLines(fmt.Sprintf("%s%d %s %s", noteMarker, n.ID, n.Ref, n.Text), opts.LineLength)
+		}
+		paras = append(paras, strings.Join(lines, "\n"))
+	}
+
+	if opts.Copyright && p.Copyright != "" {
+		paras = append(paras, wrapLines(p.Copyright, opts.LineLength))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(paras, "\n\n"))
+	return err
+}
+
+// reference returns the reference to print above a passage
+func reference(p *passage.Passage) string {
+	r := p.Reference
+	if r == "" {
+		r = p.Range.String()
+	}
+
+	if p.Translation != "" {
+		r += " (" + p.Translation + ")"
+	}
+
+	return r
+}
+
+// wrapLines wraps each line of text to width. Indented lines, such as poetry,
+// keep their indentation and hang further in when they wrap. A width of 0
+// leaves the text as it is.
+func wrapLines(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeftFunc(line, unicode.IsSpace)
+		if trimmed == "" {
+			out = append(out, "")
+			continue
+		}
+
+		indent := line[:len(line)-len(trimmed)]
+		hanging := ""
+		if indent != "" {
+			hanging = indent + "  "
+		}
+
+		for i, l := range wrap(trimmed, width-len(hanging)) {
+			if i == 0 {
+				l = indent + l
+			} else {
+				l = hanging + l
+			}
+			out = append(out, l)
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
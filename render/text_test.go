@@ -0,0 +1,170 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/ref"
+)
+
+func TestText(t *testing.T) {
+	r, _ := ref.ParseRange("John 11:35-36")
+	p := &passage.Passage{
+		Range:     *r,
+		Copyright: "Public Domain",
+		Verses:    parallelPassages[0].Verses,
+	}
+
+	cases := []struct {
+		opts passage.Options
+		out  string
+	}{
+		{
+			passage.Options{},
+			"Jesus wept. So the Jews said, \"See how he loved him!\"\n",
+		},
+		{
+			passage.Options{VerseNumbers: true, References: true, Copyright: true, LineLength: 30},
+			"John 11:35-36\n\n[35] Jesus wept. [36] So the\nJews said, \"See how he loved\nhim!\"\n\nPublic Domain\n",
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewBuffer(nil)
+		if err := Text(buf, p, c.opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != c.out {
+			t.Errorf("Text with %+v:\n%q, wanted\n%q", c.opts, buf.String(), c.out)
+		}
+	}
+}
+
+// structuredPassage has a heading, a footnote and some poetry
+var structuredPassage = &passage.Passage{
+	Reference:   "Psalm 1:1-2",
+	Translation: "ESV",
+	Copyright:   "Copyright Crossway",
+	Verses: []passage.Verse{
+		{
+			Ref:       *ref.New(ref.Psalm, 1, 1),
+			Text:      "Blessed is the man\nwho walks not in the counsel of the wicked",
+			Headings:  []passage.Heading{{Level: 1, Text: "Book One"}, {Level: 2, Text: "The Way of the Righteous"}},
+			Paragraph: true,
+			Poetry:    true,
+			Footnotes: []passage.Footnote{{Offset: 18, Text: "Or *the man*"}},
+		},
+		{
+			Ref:    *ref.New(ref.Psalm, 1, 2),
+			Text:   "but his delight is in the law of the LORD",
+			Poetry: true,
+		},
+	},
+}
+
+var structuredOptions = passage.Options{
+	VerseNumbers: true,
+	Headings:     true,
+	Subheadings:  true,
+	Footnotes:    true,
+	References:   true,
+	Copyright:    true,
+}
+
+func TestTextStructured(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := Text(buf, structuredPassage, structuredOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Psalm 1:1-2 (ESV)
+
+Book One
+
+The Way of the Righteous
+
+    [1:1] Blessed is the man(1)
+    who walks not in the counsel of the wicked
+    [2] but his delight is in the law of the LORD
+
+Footnotes
+
+(1) 1:1 Or *the man*
+
+Copyright Crossway
+`
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nwanted:\n%s", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := Text(buf, structuredPassage, passage.Options{LineLength: 30}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `    Blessed is the man
+    who walks not in the
+      counsel of the wicked
+    but his delight is in
+      the law of the LORD
+`
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nwanted:\n%s", buf.String(), expected)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	p := &passage.Passage{Verses: []passage.Verse{{
+		Ref:          *ref.New(ref.John, 11, 25),
+		Text:         "Jesus said to her, I am the resurrection and the life.",
+		WordsOfJesus: []passage.Span{{Start: 19, End: 54}},
+	}}}
+
+	buf := bytes.NewBuffer(nil)
+	if err := Terminal(buf, p, passage.Options{VerseNumbers: true}, 30, true); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\x1b[33m[25]\x1b[0m Jesus said to her, \x1b[31mI am\n" +
+		"the resurrection and the life.\x1b[0m\n"
+	if buf.String() != expected {
+		t.Errorf("got %q, wanted %q", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := Terminal(buf, p, passage.Options{}, 80, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != p.Verses[0].Text+"\n" {
+		t.Errorf("got %q without colour", buf.String())
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	p := &passage.Passage{Verses: []passage.Verse{
+		{Ref: *ref.New(ref.Romans, 8, 28), Text: "And we know", Highlight: "yellow"},
+		{Ref: *ref.New(ref.Romans, 8, 29), Text: "For those", Notes: []passage.Note{{ID: 3, Ref: "Romans 8:28-29", Text: "Golden chain"}}},
+	}}
+
+	buf := bytes.NewBuffer(nil)
+	if err := Text(buf, p, passage.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "And we know For those✎3\n\nNotes\n\n✎3 Romans 8:28-29 Golden chain\n"
+	if buf.String() != expected {
+		t.Errorf("got %q, wanted %q", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := Terminal(buf, p, passage.Options{}, 80, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "\x1b[43;30mAnd we know\x1b[0m For those\x1b[2m✎3\x1b[0m") {
+		t.Errorf("got %q in colour", buf.String())
+	}
+}
@@ -0,0 +1,50 @@
+// Package render writes passages out for people and other programs to read.
+package render
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ansiRegex matches the escape sequences used to colour text
+var ansiRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// wrap breaks text into lines no wider than width, splitting on spaces. Words
+// longer than width are left on a line of their own. Colour escape sequences
+// take up no width.
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && visibleWidth(line)+1+visibleWidth(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// visibleWidth returns the number of columns s takes up in a terminal
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiRegex.ReplaceAllString(s, ""))
+}
+
+// pad fills s with spaces on the right up to width
+func pad(s string, width int) string {
+	if n := visibleWidth(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+
+	return s
+}
@@ -0,0 +1,210 @@
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dtjm/bible/ref"
+)
+
+// Bookmark is a reference saved under a name
+type Bookmark struct {
+	Ref         string    `toml:"ref" json:"ref"`
+	Description string    `toml:"description" json:"description,omitempty"`
+	Tags        []string  `toml:"tags" json:"tags,omitempty"`
+	Created     time.Time `toml:"created" json:"created"`
+	Updated     time.Time `toml:"updated" json:"updated"`
+
+	// Previous is where the bookmark was before it was last advanced, so
+	// that the advance can be undone
+	Previous string `toml:"previous" json:"previous,omitempty"`
+
+	// Step makes the bookmark a cursor, which moves on by this much each
+	// time it is read, e.g. "2 chapters" or "pericope"
+	Step string `toml:"step" json:"step,omitempty"`
+
+	// Loop is a range a cursor goes round, starting again at the beginning
+	// once it reaches the end, e.g. "Proverbs 1-31"
+	Loop string `toml:"loop" json:"loop,omitempty"`
+}
+
+// HasTag reports whether the bookmark is tagged with tag
+func (b *Bookmark) HasTag(tag string) bool {
+	for _, t := range b.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsCursor reports whether the bookmark called name is a cursor, moving on
+// each time it is read. "next" always is, a chapter at a time unless it is
+// given another step.
+func (c *Config) IsCursor(name string) bool {
+	b, ok := c.Bookmarks[name]
+	return ok && (name == "next" || b.Step != "")
+}
+
+// Cursors returns the names of the cursors in the order they come in the
+// Bible
+func (c *Config) Cursors() []string {
+	names, _ := c.BookmarkNames("canon", "")
+	var cursors []string
+	for _, name := range names {
+		if c.IsCursor(name) {
+			cursors = append(cursors, name)
+		}
+	}
+
+	return cursors
+}
+
+// BookmarkSorts lists the orders bookmarks can be listed in: by where they
+// are in the Bible, most recently updated first, or by name
+var BookmarkSorts = []string{"canon", "recent", "name"}
+
+// Bookmark returns the reference saved under name, or "" if there is none
+func (c *Config) Bookmark(name string) string {
+	return c.Bookmarks[name].Ref
+}
+
+// SetBookmark saves a reference under name. A bookmark that already exists
+// keeps its description and tags.
+func (c *Config) SetBookmark(name, refString string) {
+	now := time.Now()
+	b, ok := c.Bookmarks[name]
+	if !ok || b.Created.IsZero() {
+		b.Created = now
+	}
+	b.Ref, b.Updated = refString, now
+	c.Bookmarks[name] = b
+}
+
+// AdvanceBookmark moves the bookmark called name on from one reference to
+// another, remembering where it was so that UndoBookmark can move it back.
+// It is left alone, and false returned, if it isn't at from, e.g. because
+// another process has already moved it on.
+func (c *Config) AdvanceBookmark(name, from, to string) bool {
+	if c.Bookmark(name) != from {
+		return false
+	}
+
+	c.SetBookmark(name, to)
+	b := c.Bookmarks[name]
+	b.Previous = from
+	c.Bookmarks[name] = b
+	return true
+}
+
+// PreviousBookmark returns where UndoBookmark would move the bookmark
+// called name back to, without moving it
+func (c *Config) PreviousBookmark(name string) (string, error) {
+	b, ok := c.Bookmarks[name]
+	if !ok {
+		return "", fmt.Errorf("You don't have a bookmark called %q", name)
+	}
+	if b.Previous == "" {
+		return "", fmt.Errorf("The %q bookmark hasn't been advanced since it was set, so there is nothing to undo", name)
+	}
+
+	return b.Previous, nil
+}
+
+// UndoBookmark moves the bookmark called name back to where it was before
+// it was last advanced, and returns where that is
+func (c *Config) UndoBookmark(name string) (string, error) {
+	previous, err := c.PreviousBookmark(name)
+	if err != nil {
+		return "", err
+	}
+
+	c.SetBookmark(name, previous)
+	b := c.Bookmarks[name]
+	b.Previous = ""
+	c.Bookmarks[name] = b
+	return b.Ref, nil
+}
+
+// EditBookmark changes the bookmark called name with f
+func (c *Config) EditBookmark(name string, f func(b *Bookmark)) error {
+	b, ok := c.Bookmarks[name]
+	if !ok {
+		return fmt.Errorf("You don't have a bookmark called %q", name)
+	}
+
+	f(&b)
+	b.Updated = time.Now()
+	c.Bookmarks[name] = b
+	return nil
+}
+
+// DeleteBookmark removes the bookmark called name
+func (c *Config) DeleteBookmark(name string) error {
+	if _, ok := c.Bookmarks[name]; !ok {
+		return fmt.Errorf("You don't have a bookmark called %q", name)
+	}
+
+	delete(c.Bookmarks, name)
+	return nil
+}
+
+// RenameBookmark moves a bookmark to a new name, which must not be taken
+func (c *Config) RenameBookmark(from, to string) error {
+	b, ok := c.Bookmarks[from]
+	if !ok {
+		return fmt.Errorf("You don't have a bookmark called %q", from)
+	}
+	if _, taken := c.Bookmarks[to]; taken {
+		return fmt.Errorf("You already have a bookmark called %q", to)
+	}
+
+	delete(c.Bookmarks, from)
+	c.Bookmarks[to] = b
+	return nil
+}
+
+// BookmarkNames returns the names of the bookmarks in one of BookmarkSorts,
+// leaving out those not tagged with tag unless it is empty. In canonical
+// order, bookmarks that can't be parsed come last.
+func (c *Config) BookmarkNames(order, tag string) ([]string, error) {
+	var names []string
+	refs := make(map[string]*ref.Range)
+	for name, b := range c.Bookmarks {
+		if tag != "" && !b.HasTag(tag) {
+			continue
+		}
+		names = append(names, name)
+		if r, err := ref.ParseRange(b.Ref); err == nil {
+			refs[name] = r
+		}
+	}
+	sort.Strings(names)
+
+	var less func(a, b string) bool
+	switch strings.ToLower(order) {
+	case "", "canon":
+		less = func(a, b string) bool {
+			ra, rb := refs[a], refs[b]
+			if ra == nil || rb == nil {
+				return ra != nil
+			}
+			return ra.Start.Less(&rb.Start)
+		}
+	case "recent":
+		less = func(a, b string) bool {
+			return c.Bookmarks[a].Updated.After(c.Bookmarks[b].Updated)
+		}
+	case "name":
+		return names, nil
+	default:
+		return nil, fmt.Errorf("Unknown order %q, expected one of %q", order, BookmarkSorts)
+	}
+
+	// Stable, so that ties stay in order of name
+	sort.SliceStable(names, func(i, j int) bool { return less(names[i], names[j]) })
+	return names, nil
+}
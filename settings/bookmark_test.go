@@ -0,0 +1,118 @@
+package settings
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBookmarks(t *testing.T) {
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	c := &Config{Bookmarks: map[string]Bookmark{
+		"next":   {Ref: "Genesis 3", Updated: day.AddDate(0, 0, 3)},
+		"psalms": {Ref: "Psalm 23", Tags: []string{"comfort"}, Updated: day.AddDate(0, 0, 1)},
+		"love":   {Ref: "1 Corinthians 13", Tags: []string{"Wedding", "love"}, Updated: day.AddDate(0, 0, 2)},
+		"todo":   {Ref: "not a reference", Updated: day},
+	}}
+
+	cases := []struct {
+		order, tag string
+		want       []string
+	}{
+		{"canon", "", []string{"next", "psalms", "love", "todo"}},
+		{"recent", "", []string{"next", "love", "psalms", "todo"}},
+		{"name", "", []string{"love", "next", "psalms", "todo"}},
+		{"canon", "wedding", []string{"love"}},
+		{"", "comfort", []string{"psalms"}},
+	}
+
+	for _, tc := range cases {
+		got, err := c.BookmarkNames(tc.order, tc.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("BookmarkNames(%q, %q) -> %v, wanted %v", tc.order, tc.tag, got, tc.want)
+		}
+	}
+
+	if _, err := c.BookmarkNames("random", ""); err == nil {
+		t.Errorf("expected an error for an unknown order")
+	}
+
+	c.Bookmarks["proverbs"] = Bookmark{Ref: "Proverbs 19", Step: "1 chapter", Loop: "Proverbs 1-31"}
+	if got, want := c.Cursors(), []string{"next", "proverbs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cursors() -> %v, wanted %v", got, want)
+	}
+}
+
+func TestEditBookmarks(t *testing.T) {
+	c := &Config{Bookmarks: make(map[string]Bookmark)}
+
+	c.SetBookmark("a", "John 3")
+	created := c.Bookmarks["a"].Created
+	if created.IsZero() || c.Bookmarks["a"].Updated.IsZero() {
+		t.Errorf("bookmark not timestamped: %+v", c.Bookmarks["a"])
+	}
+
+	err := c.EditBookmark("a", func(b *Bookmark) {
+		b.Description = "Nicodemus"
+		b.Tags = []string{"gospel"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetBookmark("a", "John 4")
+	if b := c.Bookmarks["a"]; b.Ref != "John 4" || b.Description != "Nicodemus" || !b.HasTag("Gospel") || !b.Created.Equal(created) {
+		t.Errorf("SetBookmark lost details: %+v", b)
+	}
+
+	if err := c.RenameBookmark("a", "b"); err != nil || c.Bookmark("b") != "John 4" || c.Bookmark("a") != "" {
+		t.Errorf("RenameBookmark -> %v, %v", err, c.Bookmarks)
+	}
+	c.SetBookmark("c", "Acts 1")
+	if err := c.RenameBookmark("b", "c"); err == nil {
+		t.Errorf("expected an error renaming over another bookmark")
+	}
+	if err := c.DeleteBookmark("b"); err != nil || len(c.Bookmarks) != 1 {
+		t.Errorf("DeleteBookmark -> %v, %v", err, c.Bookmarks)
+	}
+	if err := c.DeleteBookmark("b"); err == nil {
+		t.Errorf("expected an error deleting a missing bookmark")
+	}
+	if err := c.EditBookmark("z", func(*Bookmark) {}); err == nil {
+		t.Errorf("expected an error editing a missing bookmark")
+	}
+}
+
+func TestAdvanceBookmark(t *testing.T) {
+	c := &Config{Bookmarks: make(map[string]Bookmark)}
+	c.SetBookmark("next", "Genesis 1")
+
+	if _, err := c.UndoBookmark("next"); err == nil {
+		t.Errorf("expected an error undoing a bookmark that was never advanced")
+	}
+
+	if !c.AdvanceBookmark("next", "Genesis 1", "Genesis 2") || c.Bookmark("next") != "Genesis 2" {
+		t.Errorf("AdvanceBookmark didn't advance: %v", c.Bookmarks["next"])
+	}
+	if c.AdvanceBookmark("next", "Genesis 1", "Genesis 2") {
+		t.Errorf("AdvanceBookmark advanced from the wrong place")
+	}
+
+	if r, err := c.PreviousBookmark("next"); err != nil || r != "Genesis 1" || c.Bookmark("next") != "Genesis 2" {
+		t.Errorf("PreviousBookmark -> %v, %v, wanted Genesis 1 without moving", r, err)
+	}
+	if r, err := c.UndoBookmark("next"); err != nil || r != "Genesis 1" || c.Bookmark("next") != "Genesis 1" {
+		t.Errorf("UndoBookmark -> %v, %v, wanted Genesis 1", r, err)
+	}
+	if _, err := c.UndoBookmark("next"); err == nil {
+		t.Errorf("expected an error undoing twice")
+	}
+	if _, err := c.PreviousBookmark("next"); err == nil {
+		t.Errorf("expected an error peeking at an undo after undoing")
+	}
+	if _, err := c.UndoBookmark("missing"); err == nil {
+		t.Errorf("expected an error undoing a missing bookmark")
+	}
+}
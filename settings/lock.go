@@ -0,0 +1,43 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Lock is an advisory lock on a file, shared by every process that uses
+// LockFile on the same path. It is held on a separate file, path.lock, which
+// is left in place so that every process locks the same file.
+type Lock struct {
+	f *os.File
+}
+
+// LockFile waits until no other process holds the lock on path, then takes
+// it. Call Unlock when done.
+func LockFile(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &Lock{f}, nil
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	err := unlockFile(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
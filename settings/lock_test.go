@@ -0,0 +1,103 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// The tests below run copies of the test binary as separate processes, each
+// of which runs TestUpdateProcess to add to a counter kept in a bookmark
+
+const (
+	processes = 4
+	updates   = 25
+)
+
+func TestUpdateProcess(t *testing.T) {
+	path := os.Getenv("BIBLE_TEST_UPDATE_CONFIG")
+	if path == "" {
+		return
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for i := 0; i < updates; i++ {
+		err := c.Update(func(c *Config) error {
+			n, _ := strconv.Atoi(c.Bookmark("count"))
+			c.SetBookmark("count", strconv.Itoa(n+1))
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	os.Exit(0)
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	home, done := testEnv(t, nil)
+	defer done()
+
+	path := filepath.Join(home, "config.toml")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, processes)
+	for i := 0; i < processes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestUpdateProcess$")
+			cmd.Env = append(os.Environ(), "BIBLE_TEST_UPDATE_CONFIG="+path)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("%s: %s", err, out)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Bookmark("count"), strconv.Itoa(processes*updates); got != want {
+		t.Errorf("count -> %v after %d processes made %d updates each, wanted %v", got, processes, updates, want)
+	}
+}
+
+func TestUpdateError(t *testing.T) {
+	home, done := testEnv(t, nil)
+	defer done()
+
+	c, err := Load(filepath.Join(home, "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("failed")
+	err = c.Update(func(c *Config) error {
+		c.SetBookmark("next", "Revelation 1")
+		return failed
+	})
+	if err != failed {
+		t.Errorf("Update -> %v, wanted %v", err, failed)
+	}
+	if _, err := os.Stat(c.Path()); !os.IsNotExist(err) {
+		t.Errorf("file was written despite the error")
+	}
+}
@@ -0,0 +1,184 @@
+package settings
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// SchemaVersion is the version of the config file this package reads and
+// writes. Files without a schema_version are version 1.
+var SchemaVersion = len(migrations) + 1
+
+// migration upgrades the decoded contents of a config file by one version
+type migration struct {
+	Description string
+
+	// Migrate changes the data in place. Modified is when the file was last
+	// written, for settings that need a time.
+	Migrate func(data map[string]interface{}, modified time.Time) error
+}
+
+// migrations[i] upgrades a file from version i+1 to i+2. Add to the end;
+// never change a migration that has been released.
+var migrations = []migration{
+	{"Store bookmarks as tables with the reference and the time it was set", structuredBookmarks},
+	{"Record when each bookmark was created, taken to be when it was last set", bookmarkCreated},
+}
+
+// Migration describes how a config file is upgraded
+type Migration struct {
+	From, To int
+
+	// Steps describes each migration, in the order they run
+	Steps []string
+
+	// Before and After are the contents of the file
+	Before, After []byte
+
+	// Backup is where the original file is kept
+	Backup string
+}
+
+// PlanMigration works out how the file at path would be upgraded, without
+// changing anything. It returns nil if the file is already up to date, and
+// an error if it was written by a newer version of bible.
+func PlanMigration(path string) (*Migration, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	before, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{})
+	if _, err := toml.Decode(string(before), &data); err != nil {
+		return nil, fmt.Errorf("Error reading config file %s: %s", path, err)
+	}
+
+	version, err := schemaVersion(path, data)
+	if err != nil || version == SchemaVersion {
+		return nil, err
+	}
+
+	m := Migration{
+		From:   version,
+		To:     SchemaVersion,
+		Before: before,
+		Backup: fmt.Sprintf("%s.v%d.bak", path, version),
+	}
+	for _, step := range migrations[version-1:] {
+		if err := step.Migrate(data, info.ModTime()); err != nil {
+			return nil, err
+		}
+		m.Steps = append(m.Steps, step.Description)
+	}
+	data["schema_version"] = int64(SchemaVersion)
+
+	// Round trip through Config so that the file is laid out as it is when saved
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+	c := Config{RefStyle: DefaultRefStyle}
+	if _, err := toml.Decode(buf.String(), &c); err != nil {
+		return nil, fmt.Errorf("Error migrating config file %s: %s", path, err)
+	}
+	buf.Reset()
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, err
+	}
+	m.After = buf.Bytes()
+
+	return &m, nil
+}
+
+// Migrate upgrades the file at path to SchemaVersion, keeping a copy of the
+// original. It returns nil if the file is already up to date.
+func Migrate(path string) (*Migration, error) {
+	if m, err := PlanMigration(path); err != nil || m == nil {
+		return nil, err
+	}
+
+	lock, err := LockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	// Plan it again, since another process may have upgraded the file while
+	// this one waited for the lock
+	m, err := PlanMigration(path)
+	if err != nil || m == nil {
+		return nil, err
+	}
+
+	if err := WriteData(m.Backup, m.Before); err != nil {
+		return nil, err
+	}
+
+	return m, WriteData(path, m.After)
+}
+
+// schemaVersion returns the version of a decoded config file
+func schemaVersion(path string, data map[string]interface{}) (int, error) {
+	v, ok := data["schema_version"]
+	if !ok {
+		return 1, nil
+	}
+
+	n, ok := v.(int64)
+	if !ok || n < 1 {
+		return 0, fmt.Errorf("Error in config file %s: schema_version must be a positive number, not %v", path, v)
+	}
+	if int(n) > SchemaVersion {
+		return 0, fmt.Errorf("Config file %s is schema version %d, but this version of bible only understands up to %d; upgrade bible to use it", path, n, SchemaVersion)
+	}
+
+	return int(n), nil
+}
+
+// structuredBookmarks turns bookmarks from references, e.g.
+// next = "Genesis 1", into tables holding the reference and when it was set.
+// The time they were set isn't known, so they get the file's.
+func structuredBookmarks(data map[string]interface{}, modified time.Time) error {
+	old, ok := data["bookmarks"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	marks := make(map[string]interface{})
+	for name, v := range old {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("Error migrating bookmark %q: expected a reference, found %v", name, v)
+		}
+		marks[name] = map[string]interface{}{"ref": s, "updated": modified.UTC()}
+	}
+	data["bookmarks"] = marks
+
+	return nil
+}
+
+// bookmarkCreated gives each bookmark the time it was created, which is only
+// known to be no later than when it was last set
+func bookmarkCreated(data map[string]interface{}, modified time.Time) error {
+	marks, _ := data["bookmarks"].(map[string]interface{})
+	for name, v := range marks {
+		b, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("Error migrating bookmark %q: expected a table, found %v", name, v)
+		}
+		if _, ok := b["created"]; !ok && b["updated"] != nil {
+			b["created"] = b["updated"]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package settings
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const v1Config = `[bookmarks]
+next = "Exodus 3"
+last = "John 3:16"
+
+[providers.esv]
+key = "secret"
+`
+
+func TestMigrate(t *testing.T) {
+	home, done := testEnv(t, nil)
+	defer done()
+
+	path := filepath.Join(home, "config.toml")
+	ioutil.WriteFile(path, []byte(v1Config), 0600)
+	modified := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	os.Chtimes(path, modified, modified)
+
+	m, err := PlanMigration(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil || m.From != 1 || m.To != SchemaVersion || len(m.Steps) != SchemaVersion-1 {
+		t.Fatalf("PlanMigration -> %+v", m)
+	}
+	if !bytes.Contains(m.After, []byte(fmt.Sprintf("schema_version = %d", SchemaVersion))) || !bytes.Contains(m.After, []byte("[bookmarks.next]")) {
+		t.Errorf("migrated file:\n%s", m.After)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != v1Config {
+		t.Errorf("PlanMigration changed the file")
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.SchemaVersion != SchemaVersion {
+		t.Errorf("SchemaVersion -> %d, wanted %d", c.SchemaVersion, SchemaVersion)
+	}
+	next := c.Bookmarks["next"]
+	if next.Ref != "Exodus 3" || !next.Updated.Equal(modified) || !next.Created.Equal(modified) {
+		t.Errorf("next -> %+v, wanted Exodus 3 at %v", next, modified)
+	}
+	if c.Providers["esv"].Key != "secret" {
+		t.Errorf("provider settings lost: %+v", c.Providers)
+	}
+
+	if data, err := ioutil.ReadFile(path + ".v1.bak"); err != nil || string(data) != v1Config {
+		t.Errorf("backup -> %q, %v", data, err)
+	}
+
+	if m, err := PlanMigration(path); m != nil || err != nil {
+		t.Errorf("PlanMigration(up to date) -> %+v, %v", m, err)
+	}
+}
+
+func TestMigrateFromV2(t *testing.T) {
+	home, done := testEnv(t, nil)
+	defer done()
+
+	path := filepath.Join(home, "config.toml")
+	ioutil.WriteFile(path, []byte(`schema_version = 2
+[bookmarks.next]
+ref = "Exodus 3"
+updated = 2021-03-04T05:06:07Z
+`), 0600)
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	if next := c.Bookmarks["next"]; !next.Created.Equal(want) {
+		t.Errorf("next created %v, wanted %v", next.Created, want)
+	}
+	if _, err := os.Stat(path + ".v2.bak"); err != nil {
+		t.Errorf("no backup: %s", err)
+	}
+	if _, err := os.Stat(path + ".v1.bak"); !os.IsNotExist(err) {
+		t.Errorf("backup for a version the file never was")
+	}
+}
+
+func TestRefuseDowngrade(t *testing.T) {
+	home, done := testEnv(t, nil)
+	defer done()
+
+	path := filepath.Join(home, "config.toml")
+	newer := "schema_version = 99\n"
+	ioutil.WriteFile(path, []byte(newer), 0600)
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "upgrade bible") {
+		t.Errorf("Load(newer) -> %v", err)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != newer {
+		t.Errorf("newer file was changed to %q", data)
+	}
+}
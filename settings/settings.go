@@ -0,0 +1,417 @@
+// Package settings loads and saves the configuration file. Settings are
+// layered: the defaults, then the file, then BIBLE_* environment variables,
+// then any flags a command is given.
+package settings
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/plan"
+	"github.com/dtjm/bible/provider"
+	"github.com/dtjm/bible/ref"
+)
+
+const (
+	// DefaultTranslation is read when neither the config nor the locale
+	// gives a translation
+	DefaultTranslation = "ESV"
+
+	// DefaultRefStyle is how references are written unless configured
+	DefaultRefStyle = "full"
+)
+
+// localeTranslations are the translations read by default in languages
+// other than English, all of which bible-api.com serves
+var localeTranslations = map[string]string{
+	"chr": "CHEROKEE",
+	"cs":  "BKR",
+	"la":  "CLEMENTINE",
+	"pt":  "ALMEIDA",
+	"ro":  "RCCV",
+}
+
+// Config is the configuration, as read from the file with the environment
+// layered over it
+type Config struct {
+	// SchemaVersion is the version of the layout of the file
+	SchemaVersion int `toml:"schema_version"`
+
+	// Translation is read when a command isn't given one. If empty, it
+	// depends on the locale.
+	Translation string `toml:"translation"`
+
+	// Locale is a language such as "en" or "pt_BR". If empty, it comes from
+	// $LC_ALL, $LC_MESSAGES or $LANG.
+	Locale string `toml:"locale"`
+
+	// RefStyle is how references are written out, one of ref.Styles
+	RefStyle string `toml:"ref_style"`
+
+	// ConfirmNext asks, after reading the next passage, whether it was
+	// finished before moving the "next" bookmark on
+	ConfirmNext bool `toml:"confirm_next"`
+
+	Bookmarks map[string]Bookmark        `toml:"bookmarks"`
+	Providers map[string]provider.Config `toml:"providers"`
+	Read      passage.Options            `toml:"read"`
+
+	// Plans holds progress through the reading plans that have been
+	// started, by the name of the plan
+	Plans map[string]plan.Progress `toml:"plans"`
+
+	path string
+
+	// file is the configuration as it is in the file, which is what gets
+	// saved, so that settings from the environment aren't written into it
+	file *Config
+}
+
+// Dir returns the directory the config file is kept in,
+// $XDG_CONFIG_HOME/bible or ~/.config/bible
+func Dir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "bible")
+	}
+
+	return filepath.Join(os.Getenv("HOME"), ".config", "bible")
+}
+
+// CacheDir returns the directory for files that can be fetched again if they
+// are lost, $XDG_CACHE_HOME/bible or ~/.cache/bible
+func CacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "bible")
+	}
+
+	return filepath.Join(os.Getenv("HOME"), ".cache", "bible")
+}
+
+// StateDir returns the directory for records the program keeps as it is
+// used, such as the reading history, $XDG_STATE_HOME/bible or
+// ~/.local/state/bible
+func StateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "bible")
+	}
+
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "bible")
+}
+
+// DataDir returns the directory for the notes and other data kept for the
+// user, $XDG_DATA_HOME/bible or ~/.local/share/bible
+func DataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "bible")
+	}
+
+	return filepath.Join(os.Getenv("HOME"), ".local", "share", "bible")
+}
+
+// Path returns the path of the config file: $BIBLE_CONFIG if it is set, or
+// config.toml in Dir
+func Path() string {
+	if path := os.Getenv("BIBLE_CONFIG"); path != "" {
+		return path
+	}
+
+	return filepath.Join(Dir(), "config.toml")
+}
+
+// LegacyPath is where the config file used to be kept
+func LegacyPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".bible")
+}
+
+// Load reads the config file at path and layers the environment over it. A
+// missing file isn't an error. If the file is missing from the default
+// location but there is one at LegacyPath, it is moved across, and if it was
+// written for an older SchemaVersion, it is upgraded.
+func Load(path string) (*Config, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) && path == filepath.Join(Dir(), "config.toml") {
+		if err := migrateLegacy(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		m, err := Migrate(path)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			log.Printf("upgraded config file %q from schema version %d to %d, keeping the original as %q", path, m.From, m.To, m.Backup)
+		}
+	}
+
+	return load(path)
+}
+
+// load reads the config file at path, which must be up to date, and layers
+// the environment over it
+func load(path string) (*Config, error) {
+	file := &Config{
+		SchemaVersion: SchemaVersion,
+		RefStyle:      DefaultRefStyle,
+		Bookmarks:     make(map[string]Bookmark),
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		log.Printf("config file does not exist, creating %q", path)
+		file.SetBookmark("next", "Genesis 1")
+	} else if _, err := toml.DecodeFile(path, file); err != nil {
+		return nil, fmt.Errorf("Error reading config file %s: %s", path, err)
+	}
+
+	if file.Bookmarks == nil {
+		file.Bookmarks = make(map[string]Bookmark)
+	}
+	if file.Plans == nil {
+		file.Plans = make(map[string]plan.Progress)
+	}
+
+	c := *file
+	c.path = path
+	c.file = file
+	c.Providers = make(map[string]provider.Config)
+	for name, p := range file.Providers {
+		c.Providers[name] = p
+	}
+
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if c.Locale == "" {
+		c.Locale = envLocale()
+	}
+	if c.Translation == "" {
+		c.Translation = DefaultTranslation
+		if t, ok := localeTranslations[c.Language()]; ok {
+			c.Translation = t
+		}
+	}
+
+	if c.RefStyle == "" {
+		c.RefStyle = DefaultRefStyle
+	}
+	if err := ref.CheckStyle(c.RefStyle); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Path returns the path the config was loaded from
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Language returns the language part of the locale, e.g. "pt" for "pt_BR"
+func (c *Config) Language() string {
+	lang := strings.ToLower(c.Locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+
+	if lang == "" || lang == "c" || lang == "posix" {
+		return "en"
+	}
+
+	return lang
+}
+
+// Update changes the config file as one transaction. It takes the lock on
+// the file, reads it afresh, lets f change it and writes it back, so that
+// changes other processes made in the meantime aren't lost. Nothing is
+// written if f returns an error. Afterwards c holds the bookmarks and plans
+// as saved.
+func (c *Config) Update(f func(c *Config) error) error {
+	lock, err := LockFile(c.path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	fresh, err := load(c.path)
+	if err != nil {
+		return err
+	}
+
+	if err := f(fresh); err != nil {
+		return err
+	}
+	if err := fresh.save(); err != nil {
+		return err
+	}
+
+	c.Bookmarks, c.Plans, c.file = fresh.Bookmarks, fresh.Plans, fresh.file
+	return nil
+}
+
+// save writes the config back to the file it was loaded from. Settings made
+// in the environment are left out; the file keeps its own.
+func (c *Config) save() error {
+	saved := *c.file
+	saved.Bookmarks, saved.Plans = c.Bookmarks, c.Plans
+
+	return writeFile(c.path, &saved)
+}
+
+// applyEnv layers the BIBLE_* environment variables over the settings
+func (c *Config) applyEnv() error {
+	strs := map[string]*string{
+		"BIBLE_TRANSLATION": &c.Translation,
+		"BIBLE_LOCALE":      &c.Locale,
+		"BIBLE_REF_STYLE":   &c.RefStyle,
+	}
+	for name, s := range strs {
+		if v := os.Getenv(name); v != "" {
+			*s = v
+		}
+	}
+
+	bools := map[string]*bool{
+		"BIBLE_CONFIRM_NEXT":       &c.ConfirmNext,
+		"BIBLE_VERSE_NUMBERS":      &c.Read.VerseNumbers,
+		"BIBLE_HEADINGS":           &c.Read.Headings,
+		"BIBLE_SUBHEADINGS":        &c.Read.Subheadings,
+		"BIBLE_FOOTNOTES":          &c.Read.Footnotes,
+		"BIBLE_PASSAGE_REFERENCES": &c.Read.References,
+		"BIBLE_COPYRIGHT":          &c.Read.Copyright,
+	}
+	for name, b := range bools {
+		if v := os.Getenv(name); v != "" {
+			var err error
+			if *b, err = strconv.ParseBool(v); err != nil {
+				return fmt.Errorf("Error parsing %s: %s", name, err)
+			}
+		}
+	}
+
+	if v := os.Getenv("BIBLE_LINE_LENGTH"); v != "" {
+		var err error
+		if c.Read.LineLength, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("Error parsing BIBLE_LINE_LENGTH: %s", err)
+		}
+	}
+
+	// Credentials, e.g. BIBLE_ESV_KEY or BIBLE_APIBIBLE_BASE_URL
+	for _, name := range provider.Names() {
+		prefix := "BIBLE_" + strings.ToUpper(name) + "_"
+		p := c.Providers[name]
+		key, baseURL := os.Getenv(prefix+"KEY"), os.Getenv(prefix+"BASE_URL")
+		if key != "" {
+			p.Key = key
+		}
+		if baseURL != "" {
+			p.BaseURL = baseURL
+		}
+		if key != "" || baseURL != "" {
+			c.Providers[name] = p
+		}
+	}
+
+	return nil
+}
+
+// envLocale returns the locale the environment is set to
+func envLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// migrateLegacy moves a config file from LegacyPath to path, leaving the old
+// one renamed rather than deleted
+func migrateLegacy(path string) error {
+	legacy := LegacyPath()
+	data, err := ioutil.ReadFile(legacy)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var c map[string]interface{}
+	if _, err := toml.Decode(string(data), &c); err != nil {
+		return fmt.Errorf("Error reading config file %s: %s", legacy, err)
+	}
+
+	lock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	// Another process may have moved it first
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := WriteData(path, data); err != nil {
+		return err
+	}
+
+	log.Printf("moved config file %q to %q", legacy, path)
+	return os.Rename(legacy, legacy+".migrated")
+}
+
+// writeFile replaces the file at path with the encoded config
+func writeFile(path string, c *Config) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return err
+	}
+
+	return WriteData(path, buf.Bytes())
+}
+
+// WriteData replaces the file at path with data. It writes a temporary file
+// in the same directory and renames it over the old one, so that a crash
+// leaves either the old file or the new one but never a partial one. The
+// file is only readable by its owner, since it may hold API keys or
+// personal notes.
+func WriteData(path string, data []byte) error {
+	// Replace what a symlinked config file points to, not the link
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
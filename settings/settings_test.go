@@ -0,0 +1,206 @@
+package settings
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dtjm/bible/plan"
+)
+
+// testEnv points the config at a temporary home directory with the given
+// variables set, and returns a function that undoes it
+func testEnv(t *testing.T, vars map[string]string) (string, func()) {
+	home, err := ioutil.TempDir("", "bible-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"HOME", "XDG_CONFIG_HOME", "BIBLE_CONFIG", "LC_ALL", "LC_MESSAGES", "LANG",
+		"BIBLE_TRANSLATION", "BIBLE_LOCALE", "BIBLE_REF_STYLE", "BIBLE_ESV_KEY", "BIBLE_LINE_LENGTH"}
+	saved := make(map[string]string)
+	for _, name := range names {
+		saved[name] = os.Getenv(name)
+		os.Setenv(name, vars[name])
+	}
+	os.Setenv("HOME", home)
+
+	return home, func() {
+		for name, v := range saved {
+			os.Setenv(name, v)
+		}
+		os.RemoveAll(home)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	_, done := testEnv(t, nil)
+	defer done()
+
+	c, err := Load(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Translation != "ESV" || c.RefStyle != "full" || c.Language() != "en" || c.Bookmark("next") != "Genesis 1" {
+		t.Errorf("Load(missing) -> %+v", c)
+	}
+}
+
+func TestLocale(t *testing.T) {
+	cases := []struct {
+		lang, language, translation string
+	}{
+		{"", "en", "ESV"},
+		{"C", "en", "ESV"},
+		{"en_GB.UTF-8", "en", "ESV"},
+		{"pt_BR.UTF-8", "pt", "ALMEIDA"},
+		{"ro_RO", "ro", "RCCV"},
+	}
+
+	for _, c := range cases {
+		_, done := testEnv(t, map[string]string{"LANG": c.lang})
+		conf, err := Load(Path())
+		done()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conf.Language() != c.language || conf.Translation != c.translation {
+			t.Errorf("(LANG=%v) -> %v, %v, wanted %v, %v", c.lang, conf.Language(), conf.Translation, c.language, c.translation)
+		}
+	}
+}
+
+func TestEnvNotSaved(t *testing.T) {
+	home, done := testEnv(t, map[string]string{
+		"BIBLE_TRANSLATION": "WEB",
+		"BIBLE_ESV_KEY":     "from-env",
+		"BIBLE_LINE_LENGTH": "60",
+	})
+	defer done()
+
+	path := filepath.Join(home, "bible.toml")
+	ioutil.WriteFile(path, []byte("translation = \"KJV\"\n[providers.esv]\nkey = \"from-file\"\n"), 0600)
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Translation != "WEB" || c.Providers["esv"].Key != "from-env" || c.Read.LineLength != 60 {
+		t.Errorf("environment not applied: %+v", c)
+	}
+
+	err = c.Update(func(c *Config) error {
+		c.SetBookmark("last", "John 3")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("BIBLE_TRANSLATION", "")
+	os.Setenv("BIBLE_ESV_KEY", "")
+	os.Setenv("BIBLE_LINE_LENGTH", "")
+	c, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Translation != "KJV" || c.Providers["esv"].Key != "from-file" || c.Read.LineLength != 0 {
+		t.Errorf("environment was saved: %+v", c)
+	}
+	if c.Bookmark("last") != "John 3" {
+		t.Errorf("bookmark wasn't saved: %v", c.Bookmarks)
+	}
+}
+
+func TestBadEnv(t *testing.T) {
+	_, done := testEnv(t, map[string]string{"BIBLE_REF_STYLE": "osis"})
+	defer done()
+
+	if _, err := Load(Path()); err == nil {
+		t.Errorf("expected an error for an unknown ref style")
+	}
+
+	os.Setenv("BIBLE_REF_STYLE", "")
+	os.Setenv("BIBLE_LINE_LENGTH", "wide")
+	if _, err := Load(Path()); err == nil {
+		t.Errorf("expected an error for a bad line length")
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	home, done := testEnv(t, nil)
+	defer done()
+
+	legacy := filepath.Join(home, ".bible")
+	ioutil.WriteFile(legacy, []byte("[bookmarks]\nnext = \"Exodus 3\"\n"), 0644)
+
+	c, err := Load(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Bookmark("next") != "Exodus 3" {
+		t.Errorf("legacy bookmarks not read: %v", c.Bookmarks)
+	}
+
+	if c.Path() != filepath.Join(home, ".config", "bible", "config.toml") {
+		t.Errorf("Path() -> %v", c.Path())
+	}
+	if _, err := os.Stat(c.Path()); err != nil {
+		t.Errorf("config not moved: %s", err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy config still in place")
+	}
+	if _, err := os.Stat(legacy + ".migrated"); err != nil {
+		t.Errorf("legacy config not kept: %s", err)
+	}
+}
+
+func TestUpdateFile(t *testing.T) {
+	home, done := testEnv(t, nil)
+	defer done()
+
+	path := filepath.Join(home, "dir", "config.toml")
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := c.Update(func(c *Config) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = c.Update(func(c *Config) error {
+		c.Plans["year"] = plan.Progress{Start: start, Done: []int{1, 2}}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := Load(path); err != nil {
+		t.Fatal(err)
+	} else if pr := reloaded.Plans["year"]; !pr.Start.Equal(start) || !reflect.DeepEqual(pr.Done, []int{1, 2}) {
+		t.Errorf("plan progress saved as %+v", pr)
+	}
+
+	files, _ := ioutil.ReadDir(filepath.Dir(path))
+	for _, f := range files {
+		switch f.Name() {
+		case "config.toml":
+			if perm := f.Mode().Perm(); perm != 0600 {
+				t.Errorf("config file mode %v, wanted 0600", perm)
+			}
+		case "config.toml.lock":
+		default:
+			t.Errorf("left behind %s", f.Name())
+		}
+	}
+}
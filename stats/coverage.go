@@ -0,0 +1,41 @@
+package stats
+
+import "github.com/dtjm/bible/ref"
+
+// Coverage records which verses of the Bible have been read, so that
+// passages overlapping one another or covering part of a chapter count each
+// verse once
+type Coverage struct {
+	read []bool
+}
+
+// NewCoverage returns a coverage with nothing read
+func NewCoverage() *Coverage {
+	return &Coverage{read: make([]bool, ref.TotalVerses())}
+}
+
+// Add marks the verses of r as read
+func (c *Coverage) Add(r *ref.Range) {
+	v := r.Verses()
+	for i := ref.Index(&v.Start); i <= ref.Index(&v.End); i++ {
+		c.read[i] = true
+	}
+}
+
+// Count returns how many verses of r have been read, and how many verses
+// there are in r. A nil range counts the whole Bible.
+func (c *Coverage) Count(r *ref.Range) (read, total int) {
+	first, last := 0, len(c.read)-1
+	if r != nil {
+		v := r.Verses()
+		first, last = ref.Index(&v.Start), ref.Index(&v.End)
+	}
+
+	for i := first; i <= last; i++ {
+		if c.read[i] {
+			read++
+		}
+	}
+
+	return read, last - first + 1
+}
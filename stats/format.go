@@ -0,0 +1,140 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// Formats lists the formats Write can produce
+var Formats = []string{"text", "json"}
+
+// shades are the heatmap cells for chapters of which none, some, about half,
+// most and all of the verses have been read
+var shades = []string{"·", "░", "▒", "▓", "█"}
+
+const (
+	green = "\x1b[32m"
+	reset = "\x1b[0m"
+)
+
+// Write writes the stats in one of Formats. The text format ends with a
+// heatmap of the chapters read, wrapped to width and in colour if color is
+// set.
+func Write(w io.Writer, s *Stats, format string, width int, color bool) error {
+	switch strings.ToLower(format) {
+	case "", "text":
+		return writeText(w, s, width, color)
+
+	case "json":
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	return fmt.Errorf("Unknown format %q, expected one of %q", format, Formats)
+}
+
+func writeText(w io.Writer, s *Stats, width int, color bool) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Streak:\t%s (longest %s)\n", days(s.CurrentStreak), days(s.LongestStreak))
+	fmt.Fprintf(tw, "Read:\t%.1f%% of the Bible (%d of %d verses)\n", s.Percent, s.Read, s.Verses)
+	if len(s.Unopened) > 0 {
+		fmt.Fprintf(tw, "Never opened:\t%s\n", strings.Join(s.Unopened, ", "))
+	}
+	if s.Memory != nil {
+		passages := "passages"
+		if s.Memory.Cards == 1 {
+			passages = "passage"
+		}
+		fmt.Fprintf(tw, "Memorizing:\t%d %s, %d due for review\n", s.Memory.Cards, passages, s.Memory.Due)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(s.Weeks) > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(tw, "Week of\tChapters\tVerses\t\n")
+		for _, wk := range s.Weeks {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t\n", wk.Start, wk.Chapters, wk.Verses)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w)
+	return writeHeatmap(w, s.Books, width, color)
+}
+
+// writeHeatmap writes a row for each book, with its percentage read and a
+// cell for each chapter. Rows too long for width carry on beneath.
+func writeHeatmap(w io.Writer, books []Book, width int, color bool) error {
+	name := 0
+	for _, b := range books {
+		if n := utf8.RuneCountInString(b.Book); n > name {
+			name = n
+		}
+	}
+
+	// The name, then the percentage, e.g. "Genesis   12% "
+	indent := name + 7
+	cells := width - indent
+	if cells < 10 {
+		cells = 10
+	}
+
+	for _, b := range books {
+		fmt.Fprintf(w, "%-*s %4.0f%% ", name, b.Book, b.Percent)
+		for i, read := range b.Chapters {
+			if i > 0 && i%cells == 0 {
+				fmt.Fprintf(w, "\n%*s", indent, "")
+			}
+			shade := shadeOf(read)
+			if color && shade > 0 {
+				fmt.Fprint(w, green+shades[shade]+reset)
+			} else {
+				fmt.Fprint(w, shades[shade])
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shadeOf returns the index in shades for a chapter with the fraction read
+// of its verses read
+func shadeOf(read float64) int {
+	switch {
+	case read <= 0:
+		return 0
+	case read >= 1:
+		return 4
+	case read < 1.0/3:
+		return 1
+	case read < 2.0/3:
+		return 2
+	}
+
+	return 3
+}
+
+// days writes n days
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+
+	return fmt.Sprintf("%d days", n)
+}
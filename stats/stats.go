@@ -0,0 +1,182 @@
+// Package stats works out streaks and how much of the Bible has been read
+// from the reading history.
+package stats
+
+import (
+	"time"
+
+	"github.com/dtjm/bible/history"
+	"github.com/dtjm/bible/ref"
+)
+
+// dateFormat is how days are written out
+const dateFormat = "2006-01-02"
+
+// Stats summarises the reading history
+type Stats struct {
+	// CurrentStreak is the number of days in a row up to today on which
+	// something was read. A streak isn't broken until a whole day goes by
+	// without reading, so it still counts yesterday's run until today is
+	// over.
+	CurrentStreak int `json:"current_streak"`
+	LongestStreak int `json:"longest_streak"`
+
+	// Weeks are the most recent weeks, oldest first
+	Weeks []Week `json:"weeks"`
+
+	// Read is the number of different verses read, out of Verses in the
+	// Bible
+	Read    int     `json:"verses_read"`
+	Verses  int     `json:"verses"`
+	Percent float64 `json:"percent"`
+
+	Books []Book `json:"books"`
+
+	// Unopened are the books not a verse of which has been read
+	Unopened []string `json:"unopened"`
+
+	// Memory counts the passages being memorised, if there are any
+	Memory *Memory `json:"memory,omitempty"`
+}
+
+// Memory is how many passages are being memorised, and how many of them are
+// due for review today
+type Memory struct {
+	Cards int `json:"cards"`
+	Due   int `json:"due"`
+}
+
+// Week is what was read in the week starting on Monday Start
+type Week struct {
+	Start string `json:"start"`
+
+	// Chapters is the number of chapters read from, in full or in part
+	Chapters int `json:"chapters"`
+
+	// Verses is the number of different verses read
+	Verses int `json:"verses"`
+}
+
+// Book is how much of a book has been read
+type Book struct {
+	Book    string  `json:"book"`
+	Read    int     `json:"verses_read"`
+	Verses  int     `json:"verses"`
+	Percent float64 `json:"percent"`
+
+	// Chapters holds the fraction of each chapter read, from 0 to 1
+	Chapters []float64 `json:"chapters"`
+}
+
+// Compute works out the stats for entries as of now, with the given number
+// of weeks. Days and weeks are in now's time zone.
+func Compute(entries []history.Entry, now time.Time, weeks int) *Stats {
+	s := &Stats{Unopened: []string{}}
+	s.CurrentStreak, s.LongestStreak = streaks(entries, now)
+
+	monday := startOfWeek(now)
+	for i := weeks - 1; i >= 0; i-- {
+		start := monday.AddDate(0, 0, -7*i)
+		s.Weeks = append(s.Weeks, week(entries, start, start.AddDate(0, 0, 7)))
+	}
+
+	c := NewCoverage()
+	for _, e := range entries {
+		c.Add(&e.Range)
+	}
+
+	s.Read, s.Verses = c.Count(nil)
+	s.Percent = percent(s.Read, s.Verses)
+	for b := ref.Genesis; b <= ref.Revelation; b++ {
+		book := Book{Book: b.String()}
+		whole := &ref.Range{Start: *ref.New(b, 0, 0), End: *ref.New(b, 0, 0)}
+		book.Read, book.Verses = c.Count(whole)
+		book.Percent = percent(book.Read, book.Verses)
+		for ch := 1; ch <= b.Chapters(); ch++ {
+			chapter := &ref.Range{Start: *ref.New(b, ch, 0), End: *ref.New(b, ch, 0)}
+			read, verses := c.Count(chapter)
+			book.Chapters = append(book.Chapters, float64(read)/float64(verses))
+		}
+
+		s.Books = append(s.Books, book)
+		if book.Read == 0 {
+			s.Unopened = append(s.Unopened, book.Book)
+		}
+	}
+
+	return s
+}
+
+// streaks returns the current and longest runs of days with something read
+func streaks(entries []history.Entry, now time.Time) (current, longest int) {
+	days := make(map[string]bool)
+	for _, e := range entries {
+		days[e.Time.In(now.Location()).Format(dateFormat)] = true
+	}
+
+	// Every run starts on a day after one without reading
+	for day := range days {
+		t, _ := time.ParseInLocation(dateFormat, day, now.Location())
+		if days[t.AddDate(0, 0, -1).Format(dateFormat)] {
+			continue
+		}
+
+		n := 1
+		for days[t.AddDate(0, 0, n).Format(dateFormat)] {
+			n++
+		}
+		if n > longest {
+			longest = n
+		}
+	}
+
+	day := now
+	if !days[day.Format(dateFormat)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day.Format(dateFormat)] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return current, longest
+}
+
+// week counts what was read from start up to end
+func week(entries []history.Entry, start, end time.Time) Week {
+	c := NewCoverage()
+	chapters := make(map[ref.Ref]bool)
+	for _, e := range entries {
+		if e.Time.Before(start) || !e.Time.Before(end) {
+			continue
+		}
+
+		c.Add(&e.Range)
+		v := e.Range.Verses()
+		last := ref.New(v.End.Book(), v.End.Chapter(), 0)
+		for ch := ref.New(v.Start.Book(), v.Start.Chapter(), 0); ; ch = ch.NextChapter() {
+			chapters[*ch] = true
+			if *ch == *last {
+				break
+			}
+		}
+	}
+
+	read, _ := c.Count(nil)
+	return Week{Start: start.Format(dateFormat), Chapters: len(chapters), Verses: read}
+}
+
+// startOfWeek returns midnight on the Monday of the week t is in
+func startOfWeek(t time.Time) time.Time {
+	days := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, t.Location())
+}
+
+// percent returns n as a percentage of total, to one decimal place
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(n*1000/total) / 10
+}
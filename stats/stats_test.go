@@ -0,0 +1,166 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtjm/bible/history"
+	"github.com/dtjm/bible/ref"
+)
+
+func entry(t *testing.T, when, refString string) history.Entry {
+	tm, err := time.Parse(time.RFC3339, when)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ref.ParseRange(refString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return history.Entry{Time: tm, Command: "read", Range: *r, Translation: "ESV"}
+}
+
+func rng(t *testing.T, s string) *ref.Range {
+	r, err := ref.ParseRange(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func TestCoverage(t *testing.T) {
+	c := NewCoverage()
+	c.Add(rng(t, "John 3:1-10"))
+	c.Add(rng(t, "John 3:5-20"))
+	c.Add(rng(t, "Jude"))
+
+	cases := []struct {
+		r     string
+		read  int
+		total int
+	}{
+		{"John 3", 20, 36},
+		{"John 3:15-25", 6, 11},
+		{"John 4", 0, 54},
+		{"Jude", 25, 25},
+		{"Jude-Revelation", 25, 429},
+	}
+
+	for _, c2 := range cases {
+		if read, total := c.Count(rng(t, c2.r)); read != c2.read || total != c2.total {
+			t.Errorf("Count(%v) -> %d, %d, wanted %d, %d", c2.r, read, total, c2.read, c2.total)
+		}
+	}
+
+	if read, total := c.Count(nil); read != 45 || total != 31102 {
+		t.Errorf("Count(nil) -> %d, %d, wanted 45, 31102", read, total)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	// Sunday, 18 October 2026
+	now, _ := time.Parse(time.RFC3339, "2026-10-18T20:00:00Z")
+	entries := []history.Entry{
+		entry(t, "2026-10-01T07:00:00Z", "Genesis 1"),
+		entry(t, "2026-10-02T07:00:00Z", "Genesis 2"),
+		entry(t, "2026-10-03T07:00:00Z", "Genesis 3"),
+		entry(t, "2026-10-16T07:00:00Z", "John 3:16"),
+		entry(t, "2026-10-17T07:00:00Z", "John 3:16-18"),
+		entry(t, "2026-10-17T08:00:00Z", "John 4-5"),
+	}
+
+	s := Compute(entries, now, 2)
+	if s.CurrentStreak != 2 || s.LongestStreak != 3 {
+		t.Errorf("streaks -> %d, %d, wanted 2, 3", s.CurrentStreak, s.LongestStreak)
+	}
+
+	want := []Week{{"2026-10-05", 0, 0}, {"2026-10-12", 3, 3 + 54 + 47}}
+	if len(s.Weeks) != len(want) {
+		t.Fatalf("Weeks -> %v, wanted %v", s.Weeks, want)
+	}
+	for i := range want {
+		if s.Weeks[i] != want[i] {
+			t.Errorf("Weeks[%d] -> %v, wanted %v", i, s.Weeks[i], want[i])
+		}
+	}
+
+	if s.Read != 31+25+24+3+54+47 || s.Verses != 31102 {
+		t.Errorf("Read -> %d of %d", s.Read, s.Verses)
+	}
+
+	john := s.Books[ref.John-ref.Genesis]
+	if john.Book != "John" || john.Read != 104 || john.Percent != 11.8 {
+		t.Errorf("John -> %+v", john)
+	}
+	if len(john.Chapters) != 21 || john.Chapters[2] != 3.0/36 || john.Chapters[3] != 1 || john.Chapters[5] != 0 {
+		t.Errorf("John chapters -> %v", john.Chapters)
+	}
+
+	if len(s.Unopened) != 64 || s.Unopened[0] != "Exodus" {
+		t.Errorf("Unopened -> %v", s.Unopened)
+	}
+
+	// Nothing read yesterday or today
+	if s := Compute(entries, now.AddDate(0, 0, 2), 0); s.CurrentStreak != 0 {
+		t.Errorf("CurrentStreak two days later -> %d, wanted 0", s.CurrentStreak)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	s := &Stats{
+		CurrentStreak: 1,
+		LongestStreak: 4,
+		Read:          10,
+		Verses:        20,
+		Percent:       50,
+		Books: []Book{
+			{Book: "Ruth", Percent: 50, Chapters: []float64{1, 0.5, 0.1, 0}},
+			{Book: "Obadiah", Chapters: []float64{0}},
+		},
+		Unopened: []string{"Obadiah"},
+		Memory:   &Memory{Cards: 3, Due: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, s, "text", 17, false); err != nil {
+		t.Fatal(err)
+	}
+	want := "Streak:        1 day (longest 4 days)\n" +
+		"Read:          50.0% of the Bible (10 of 20 verses)\n" +
+		"Never opened:  Obadiah\n" +
+		"Memorizing:    3 passages, 1 due for review\n" +
+		"\n" +
+		"Ruth      50% █▒░·\n" +
+		"Obadiah    0% ·\n"
+	if buf.String() != want {
+		t.Errorf("Write(text) -> %q, wanted %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, s, "json", 80, false); err != nil {
+		t.Fatal(err)
+	}
+	var got Stats
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || got.LongestStreak != 4 || len(got.Books) != 2 {
+		t.Errorf("Write(json) didn't round trip: %+v, %v", got, err)
+	}
+
+	if err := Write(&buf, s, "yaml", 80, false); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestHeatmapWraps(t *testing.T) {
+	var buf bytes.Buffer
+	writeHeatmap(&buf, []Book{{Book: "Jude", Chapters: make([]float64, 25)}}, 20, false)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[1] != strings.Repeat(" ", 11)+strings.Repeat("·", 10) {
+		t.Errorf("writeHeatmap -> %q", buf.String())
+	}
+}
@@ -0,0 +1,82 @@
+package term
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const morePrompt = "--More-- (Enter for the next page, q to quit)"
+
+// Page writes text to out. If out is a terminal and the text is taller than
+// it, the text goes through $PAGER, or a simple built-in pager reading from in
+// if $PAGER is not set.
+func Page(text string, out, in *os.File) error {
+	if !IsTerminal(out) {
+		_, err := io.WriteString(out, text)
+		return err
+	}
+
+	height := Height(out)
+	if strings.Count(text, "\n") < height {
+		_, err := io.WriteString(out, text)
+		return err
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		return builtinPager(text, height, out, in)
+	}
+
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+
+	// Like git, have less quit if the text fits after all and pass colours
+	// through
+	cmd.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+
+	return cmd.Run()
+}
+
+// builtinPager writes a screenful of text at a time to out, waiting for a line
+// from in before each of the rest
+func builtinPager(text string, height int, out io.Writer, in io.Reader) error {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	keys := bufio.NewReader(in)
+
+	page := height - 1
+	for start := 0; start < len(lines); start += page {
+		if start > 0 {
+			fmt.Fprint(out, morePrompt)
+			key, err := keys.ReadString('\n')
+
+			// Clear the prompt (and the user's input) before carrying on
+			fmt.Fprint(out, "\x1b[1A\r\x1b[K")
+			if strings.TrimSpace(key) == "q" || err != nil {
+				return nil
+			}
+		}
+
+		end := start + page
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		if _, err := io.WriteString(out, strings.Join(lines[start:end], "")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
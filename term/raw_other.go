@@ -0,0 +1,22 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package term
+
+import (
+	"errors"
+	"os"
+)
+
+// State is the state of a terminal before it was put into raw mode
+type State struct{}
+
+// MakeRaw is not supported on this platform
+func MakeRaw(f *os.File) (*State, error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+// Restore is not supported on this platform
+func Restore(f *os.File, s *State) error {
+	return nil
+}
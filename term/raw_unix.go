@@ -0,0 +1,55 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package term
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// State is the state of a terminal before it was put into raw mode
+type State struct {
+	termios syscall.Termios
+}
+
+// MakeRaw puts the terminal attached to f into raw mode, where keys are read
+// one at a time without being echoed, and returns its previous state for
+// Restore
+func MakeRaw(f *os.File) (*State, error) {
+	var old State
+	if err := termios(f, ioctlGetTermios, &old.termios); err != nil {
+		return nil, err
+	}
+
+	t := old.termios
+	t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	t.Oflag &^= syscall.OPOST
+	t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	t.Cflag &^= syscall.CSIZE | syscall.PARENB
+	t.Cflag |= syscall.CS8
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+
+	if err := termios(f, ioctlSetTermios, &t); err != nil {
+		return nil, err
+	}
+
+	return &old, nil
+}
+
+// Restore returns the terminal attached to f to a state saved by MakeRaw
+func Restore(f *os.File, s *State) error {
+	return termios(f, ioctlSetTermios, &s.termios)
+}
+
+func termios(f *os.File, req uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
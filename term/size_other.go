@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package term
+
+import "os"
+
+// size is not supported on this platform
+func size(f *os.File) (width, height int, ok bool) {
+	return 0, 0, false
+}
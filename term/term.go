@@ -0,0 +1,66 @@
+// Package term inspects the terminal the program is running in.
+package term
+
+import (
+	"os"
+	"strconv"
+)
+
+const (
+	// DefaultWidth is assumed when the width of the terminal cannot be found
+	DefaultWidth = 80
+
+	// DefaultHeight is assumed when the height of the terminal cannot be
+	// found
+	DefaultHeight = 24
+)
+
+// Width returns the width of the terminal attached to f in columns. $COLUMNS
+// takes precedence, which also lets users override the width when output is
+// piped.
+func Width(f *os.File) int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+
+	if w, _, ok := size(f); ok && w > 0 {
+		return w
+	}
+
+	return DefaultWidth
+}
+
+// Height returns the height of the terminal attached to f in lines, or
+// $LINES if it is set
+func Height(f *os.File) int {
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		return n
+	}
+
+	if _, h, ok := size(f); ok && h > 0 {
+		return h
+	}
+
+	return DefaultHeight
+}
+
+// IsTerminal reports whether f is a terminal
+func IsTerminal(f *os.File) bool {
+	_, _, ok := size(f)
+	return ok
+}
+
+// ColorEnabled reports whether colour should be written to f: it must be a
+// terminal, $NO_COLOR must not be set (see https://no-color.org) and $TERM
+// must not be "dumb"
+func ColorEnabled(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	return IsTerminal(f)
+}
@@ -0,0 +1,60 @@
+package term
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColorEnabled(t *testing.T) {
+	f, err := ioutil.TempFile("", "term")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if ColorEnabled(f) {
+		t.Errorf("colour enabled for a file")
+	}
+
+	os.Setenv("NO_COLOR", "")
+	defer os.Unsetenv("NO_COLOR")
+	if ColorEnabled(os.Stdout) {
+		t.Errorf("colour enabled with NO_COLOR set")
+	}
+}
+
+func TestWidth(t *testing.T) {
+	os.Setenv("COLUMNS", "123")
+	defer os.Unsetenv("COLUMNS")
+
+	if w := Width(os.Stdout); w != 123 {
+		t.Errorf("got width %d, wanted $COLUMNS", w)
+	}
+}
+
+func TestBuiltinPager(t *testing.T) {
+	text := "1\n2\n3\n4\n5\n6\n7\n"
+
+	cases := []struct {
+		keys string
+		out  string
+	}{
+		{"\n\n", "1\n2\n3\n" + morePrompt + "\x1b[1A\r\x1b[K4\n5\n6\n" + morePrompt + "\x1b[1A\r\x1b[K7\n"},
+		{"q\n", "1\n2\n3\n" + morePrompt + "\x1b[1A\r\x1b[K"},
+	}
+
+	for _, c := range cases {
+		out := bytes.NewBuffer(nil)
+		if err := builtinPager(text, 4, out, strings.NewReader(c.keys)); err != nil {
+			t.Fatal(err)
+		}
+
+		if out.String() != c.out {
+			t.Errorf("keys %q: got %q, wanted %q", c.keys, out.String(), c.out)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+// Package tui is a full-screen, interactive reader for the terminal.
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/provider"
+	"github.com/dtjm/bible/ref"
+	"github.com/dtjm/bible/render"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	exitAltScreen  = "\x1b[?25h\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+	reverseVideo   = "\x1b[7m"
+	resetStyle     = "\x1b[0m"
+
+	help = "n/p chapter  j/k scroll  g go to  / search  m mark  v verses  f notes  q quit"
+)
+
+// Reader reads the Bible a chapter at a time. The terminal must already be in
+// raw mode.
+type Reader struct {
+	// Position is the chapter being read, and is kept up to date as the
+	// reader moves around
+	Position ref.Ref
+	Options  passage.Options
+
+	Width, Height int
+	Color         bool
+
+	// Fetch fetches the text of a passage
+	Fetch func(r ref.Range, opts passage.Options) (*passage.Passage, error)
+
+	// Search finds verses containing words. If nil, searching is disabled.
+	Search func(words string) ([]provider.SearchResult, error)
+
+	// Bookmark saves a bookmark. If nil, bookmarking is disabled.
+	Bookmark func(name string, r ref.Ref) error
+
+	// Moved is called whenever the position changes, so it can be saved for
+	// the next session
+	Moved func(r ref.Ref)
+
+	keys *bufio.Reader
+	out  io.Writer
+
+	current *passage.Passage
+	lines   []string
+	scroll  int
+	status  string
+
+	results  []provider.SearchResult
+	selected int
+}
+
+// Run reads keys from in and draws to out until the user quits
+func (r *Reader) Run(in io.Reader, out io.Writer) error {
+	r.keys = bufio.NewReader(in)
+	r.out = out
+
+	fmt.Fprint(out, enterAltScreen)
+	defer fmt.Fprint(out, exitAltScreen)
+
+	r.load()
+	for {
+		r.draw("")
+
+		key, err := r.readKey()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if r.results != nil {
+			r.searchKey(key)
+			continue
+		}
+
+		switch key {
+		case "q", "ctrl-c":
+			return nil
+		case "n", "l", "right":
+			r.move(r.Position.NextChapter())
+		case "p", "h", "left":
+			r.move(r.Position.PrevChapter())
+		case "j", "down", "enter":
+			r.scrollBy(1)
+		case "k", "up":
+			r.scrollBy(-1)
+		case " ", "pgdown":
+			r.scrollBy(r.textHeight() - 1)
+		case "b", "pgup":
+			r.scrollBy(-(r.textHeight() - 1))
+		case "g":
+			r.goTo()
+		case "/":
+			r.search()
+		case "m":
+			r.mark()
+		case "v":
+			r.Options.VerseNumbers = !r.Options.VerseNumbers
+			r.layout(r.scroll)
+		case "f":
+			// Footnotes have to be asked for from the provider
+			r.Options.Footnotes = !r.Options.Footnotes
+			r.load()
+		}
+	}
+}
+
+// load fetches the current chapter and lays it out from the top
+func (r *Reader) load() {
+	p, err := r.Fetch(ref.Range{Start: r.Position, End: r.Position}, r.Options)
+	if err != nil {
+		r.lines = nil
+		r.status = err.Error()
+		return
+	}
+
+	r.status = ""
+	r.current = p
+	r.layout(0)
+}
+
+// layout renders the passage into lines for the screen
+func (r *Reader) layout(scroll int) {
+	r.lines = nil
+	if r.current != nil {
+		buf := bytes.NewBuffer(nil)
+		render.Terminal(buf, r.current, r.Options, r.Width, r.Color)
+		r.lines = strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	}
+
+	r.scroll = 0
+	r.scrollBy(scroll)
+}
+
+func (r *Reader) move(to *ref.Ref) {
+	r.Position = *to
+	if r.Moved != nil {
+		r.Moved(r.Position)
+	}
+	r.load()
+}
+
+func (r *Reader) scrollBy(n int) {
+	r.scroll += n
+	if max := len(r.lines) - r.textHeight(); r.scroll > max {
+		r.scroll = max
+	}
+	if r.scroll < 0 {
+		r.scroll = 0
+	}
+}
+
+// textHeight is the number of lines available for text, leaving room for the
+// status line and, when it is open, the search pane
+func (r *Reader) textHeight() int {
+	h := r.Height - 1
+	if r.results != nil {
+		h -= r.paneHeight()
+	}
+
+	return h
+}
+
+func (r *Reader) paneHeight() int {
+	return (r.Height - 1) / 2
+}
+
+// goTo prompts for a reference and jumps to its chapter
+func (r *Reader) goTo() {
+	s, ok := r.prompt("Go to: ")
+	if !ok || s == "" {
+		return
+	}
+
+	to, err := ref.Parse(s)
+	if err != nil {
+		r.status = err.Error()
+		return
+	}
+
+	r.move(chapterOf(to))
+}
+
+// chapterOf returns the chapter a reference is in, or the first chapter of a
+// book
+func chapterOf(to *ref.Ref) *ref.Ref {
+	chapter := to.Chapter()
+	if chapter == 0 {
+		chapter = 1
+	}
+
+	return ref.New(to.Book(), chapter, 0)
+}
+
+// search prompts for words and opens the search pane with the results
+func (r *Reader) search() {
+	if r.Search == nil {
+		r.status = "Searching isn't available for this translation"
+		return
+	}
+
+	s, ok := r.prompt("Search: ")
+	if !ok || s == "" {
+		return
+	}
+
+	results, err := r.Search(s)
+	if err != nil {
+		r.status = err.Error()
+		return
+	}
+
+	if len(results) == 0 {
+		r.status = fmt.Sprintf("No results for %q", s)
+		return
+	}
+
+	r.results = results
+	r.selected = 0
+	r.scrollBy(0)
+}
+
+// searchKey handles keys while the search pane is open
+func (r *Reader) searchKey(key string) {
+	switch key {
+	case "j", "down":
+		if r.selected < len(r.results)-1 {
+			r.selected++
+		}
+	case "k", "up":
+		if r.selected > 0 {
+			r.selected--
+		}
+	case "enter":
+		to, err := ref.Parse(r.results[r.selected].Reference)
+		r.results = nil
+		if err != nil {
+			r.status = err.Error()
+			return
+		}
+		r.move(chapterOf(to))
+	case "esc", "q", "ctrl-c":
+		r.results = nil
+		r.scrollBy(0)
+	}
+}
+
+// mark prompts for a name and bookmarks the current chapter under it
+func (r *Reader) mark() {
+	if r.Bookmark == nil {
+		return
+	}
+
+	name, ok := r.prompt("Bookmark name: ")
+	if !ok || name == "" {
+		return
+	}
+
+	if err := r.Bookmark(name, r.Position); err != nil {
+		r.status = err.Error()
+		return
+	}
+
+	r.status = fmt.Sprintf("Bookmarked %s as %q", r.Position.String(), name)
+}
+
+// prompt reads a line of input on the status line. It returns false if the
+// user cancels with escape.
+func (r *Reader) prompt(label string) (string, bool) {
+	var input []rune
+	for {
+		r.draw(label + string(input) + "_")
+
+		key, err := r.readKey()
+		if err != nil {
+			return "", false
+		}
+
+		switch key {
+		case "enter":
+			return strings.TrimSpace(string(input)), true
+		case "esc", "ctrl-c":
+			return "", false
+		case "backspace":
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+		default:
+			if len([]rune(key)) == 1 {
+				input = append(input, []rune(key)...)
+			}
+		}
+	}
+}
+
+// draw redraws the screen, with line as the status line if it is given
+func (r *Reader) draw(line string) {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString(clearScreen)
+
+	h := r.textHeight()
+	for i := 0; i < h; i++ {
+		if n := r.scroll + i; n < len(r.lines) {
+			buf.WriteString(r.lines[n])
+		}
+		buf.WriteString("\r\n")
+	}
+
+	if r.results != nil {
+		r.drawResults(buf)
+	}
+
+	if line == "" {
+		line = r.status
+	}
+	if line == "" {
+		line = fmt.Sprintf("%s (%s)  %s", r.Position.String(), r.translation(), help)
+	}
+	if len([]rune(line)) > r.Width {
+		line = string([]rune(line)[:r.Width])
+	}
+	buf.WriteString(reverseVideo + line + resetStyle)
+
+	r.out.Write(buf.Bytes())
+}
+
+// drawResults draws the search pane, keeping the selected result in view
+func (r *Reader) drawResults(buf *bytes.Buffer) {
+	h := r.paneHeight()
+	first := 0
+	if r.selected >= h {
+		first = r.selected - h + 1
+	}
+
+	for i := first; i < first+h; i++ {
+		if i < len(r.results) {
+			line := r.results[i].Reference + "  " + r.results[i].Text
+			if len([]rune(line)) > r.Width {
+				line = string([]rune(line)[:r.Width])
+			}
+
+			if i == r.selected {
+				line = reverseVideo + line + resetStyle
+			}
+			buf.WriteString(line)
+		}
+		buf.WriteString("\r\n")
+	}
+}
+
+func (r *Reader) translation() string {
+	if r.current == nil {
+		return ""
+	}
+
+	return r.current.Translation
+}
+
+// readKey reads a key press, turning escape sequences and control characters
+// into names such as "up" and "enter"
+func (r *Reader) readKey() (string, error) {
+	c, _, err := r.keys.ReadRune()
+	if err != nil {
+		return "", err
+	}
+
+	switch c {
+	case '\r', '\n':
+		return "enter", nil
+	case 0x7f, 0x08:
+		return "backspace", nil
+	case 0x03:
+		return "ctrl-c", nil
+	case 0x1b:
+		// A lone escape has nothing following it
+		if r.keys.Buffered() == 0 {
+			return "esc", nil
+		}
+
+		seq := []byte{}
+		for r.keys.Buffered() > 0 {
+			b, _ := r.keys.ReadByte()
+			seq = append(seq, b)
+			if b >= 'A' && b <= 'Z' || b == '~' {
+				break
+			}
+		}
+
+		switch string(seq) {
+		case "[A":
+			return "up", nil
+		case "[B":
+			return "down", nil
+		case "[C":
+			return "right", nil
+		case "[D":
+			return "left", nil
+		case "[5~":
+			return "pgup", nil
+		case "[6~":
+			return "pgdown", nil
+		}
+		return "esc", nil
+	}
+
+	return string(c), nil
+}
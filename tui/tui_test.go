@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/dtjm/bible/passage"
+	"github.com/dtjm/bible/provider"
+	"github.com/dtjm/bible/ref"
+)
+
+// newReader returns a reader whose passages say which chapter they are, and
+// which records the chapters it fetched
+func newReader(start *ref.Ref, fetched *[]string) *Reader {
+	return &Reader{
+		Position: *start,
+		Width:    80,
+		Height:   10,
+		Fetch: func(r ref.Range, opts passage.Options) (*passage.Passage, error) {
+			*fetched = append(*fetched, r.Start.String())
+			return &passage.Passage{
+				Range:       r,
+				Translation: "TEST",
+				Verses: []passage.Verse{
+					{Ref: *ref.New(r.Start.Book(), r.Start.Chapter(), 1), Text: "Text of " + r.Start.String()},
+				},
+			}, nil
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		start *ref.Ref
+		keys  string
+		want  string
+	}{
+		{ref.New(ref.John, 3, 0), "q", "John 3"},
+		{ref.New(ref.John, 3, 0), "nnq", "John 5"},
+		{ref.New(ref.John, 3, 0), "\x1b[C\x1b[Dpq", "John 2"},
+		{ref.New(ref.Genesis, 1, 0), "p", "Revelation 22"},
+		{ref.New(ref.John, 3, 0), "grom 8:28\rq", "Romans 8"},
+		{ref.New(ref.John, 3, 0), "gphilemon\r", "Philemon 1"},
+		{ref.New(ref.John, 3, 0), "gnot a book\rq", "John 3"},
+		{ref.New(ref.John, 3, 0), "gmatt\x7f\x7frk 4\r", "Mark 4"},
+	}
+
+	for _, c := range cases {
+		var fetched []string
+		r := newReader(c.start, &fetched)
+		if err := r.Run(strings.NewReader(c.keys), ioutil.Discard); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := r.Position.String(); got != c.want {
+			t.Errorf("%q from %v -> %v, wanted %v", c.keys, c.start, got, c.want)
+		}
+		if last := fetched[len(fetched)-1]; last != c.want {
+			t.Errorf("%q from %v fetched %v last, wanted %v", c.keys, c.start, last, c.want)
+		}
+	}
+}
+
+func TestMoved(t *testing.T) {
+	var fetched, moved []string
+	r := newReader(ref.New(ref.John, 3, 0), &fetched)
+	r.Moved = func(to ref.Ref) {
+		moved = append(moved, to.String())
+	}
+
+	if err := r.Run(strings.NewReader("np"), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(moved) != "[John 4 John 3]" {
+		t.Errorf("moved to %v, wanted [John 4 John 3]", moved)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	var fetched []string
+	r := newReader(ref.New(ref.John, 3, 0), &fetched)
+	r.Search = func(words string) ([]provider.SearchResult, error) {
+		return []provider.SearchResult{
+			{Reference: "John 3:16", Text: "For God so " + words},
+			{Reference: "1 John 4:9", Text: "In this the " + words},
+		}, nil
+	}
+
+	if err := r.Run(strings.NewReader("/loved\rj\r"), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := r.Position.String(); got != "1 John 4" {
+		t.Errorf("search jumped to %v, wanted 1 John 4", got)
+	}
+	if r.results != nil {
+		t.Errorf("search pane still open after jumping")
+	}
+}
+
+func TestBookmark(t *testing.T) {
+	var fetched []string
+	marks := make(map[string]string)
+	r := newReader(ref.New(ref.John, 3, 0), &fetched)
+	r.Bookmark = func(name string, at ref.Ref) error {
+		marks[name] = at.String()
+		return nil
+	}
+
+	if err := r.Run(strings.NewReader("nmfav\rq"), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	if marks["fav"] != "John 4" {
+		t.Errorf("bookmarks are %v, wanted fav at John 4", marks)
+	}
+}
+
+func TestToggle(t *testing.T) {
+	var fetched []string
+	r := newReader(ref.New(ref.John, 3, 0), &fetched)
+	r.Options.VerseNumbers = true
+
+	if err := r.Run(strings.NewReader("vfq"), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Options.VerseNumbers || !r.Options.Footnotes {
+		t.Errorf("options are %+v, wanted verse numbers off and footnotes on", r.Options)
+	}
+
+	// Only footnotes need the passage fetching again
+	if len(fetched) != 2 {
+		t.Errorf("fetched %v, wanted John 3 twice", fetched)
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	cases := []struct {
+		in   string
+		keys []string
+	}{
+		{"ab", []string{"a", "b"}},
+		{"\x1b[A\x1b[B\r", []string{"up", "down", "enter"}},
+		{"\x1b[6~\x1b[5~\x7f", []string{"pgdown", "pgup", "backspace"}},
+		{"\x1b", []string{"esc"}},
+	}
+
+	for _, c := range cases {
+		var fetched []string
+		r := newReader(ref.New(ref.John, 3, 0), &fetched)
+		r.Run(strings.NewReader(""), ioutil.Discard)
+		r.keys.Reset(strings.NewReader(c.in))
+
+		var keys []string
+		for {
+			k, err := r.readKey()
+			if err != nil {
+				break
+			}
+			keys = append(keys, k)
+		}
+
+		if fmt.Sprint(keys) != fmt.Sprint(c.keys) {
+			t.Errorf("readKey(%q) -> %v, wanted %v", c.in, keys, c.keys)
+		}
+	}
+}
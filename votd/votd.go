@@ -0,0 +1,146 @@
+// Package votd picks a verse of the day. The choice depends only on the date,
+// so everyone sees the same verse on the same day.
+package votd
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/dtjm/bible/ref"
+)
+
+// DateFormat is the layout of the dates the choice is seeded with
+const DateFormat = "2006-01-02"
+
+// Curated is the list of well-loved verses the verse of the day is normally
+// chosen from. Append to it rather than reordering it, or the verse for a day
+// that has already passed will change.
+var Curated = []string{
+	"Genesis 1:1",
+	"Genesis 50:20",
+	"Exodus 14:14",
+	"Numbers 6:24-26",
+	"Deuteronomy 6:4-5",
+	"Deuteronomy 31:6",
+	"Joshua 1:9",
+	"Joshua 24:15",
+	"1 Samuel 16:7",
+	"2 Chronicles 7:14",
+	"Nehemiah 8:10",
+	"Job 19:25",
+	"Psalm 1:1-2",
+	"Psalm 16:11",
+	"Psalm 19:14",
+	"Psalm 23:1",
+	"Psalm 27:1",
+	"Psalm 34:8",
+	"Psalm 37:4",
+	"Psalm 46:1",
+	"Psalm 46:10",
+	"Psalm 51:10",
+	"Psalm 55:22",
+	"Psalm 90:12",
+	"Psalm 103:12",
+	"Psalm 118:24",
+	"Psalm 119:105",
+	"Psalm 121:1-2",
+	"Psalm 139:14",
+	"Proverbs 3:5-6",
+	"Proverbs 16:3",
+	"Proverbs 18:10",
+	"Ecclesiastes 3:1",
+	"Isaiah 9:6",
+	"Isaiah 26:3",
+	"Isaiah 40:31",
+	"Isaiah 41:10",
+	"Isaiah 53:5",
+	"Isaiah 55:8-9",
+	"Jeremiah 29:11",
+	"Lamentations 3:22-23",
+	"Micah 6:8",
+	"Habakkuk 3:17-18",
+	"Zephaniah 3:17",
+	"Matthew 5:14-16",
+	"Matthew 6:33",
+	"Matthew 6:34",
+	"Matthew 11:28-30",
+	"Matthew 22:37-39",
+	"Matthew 28:19-20",
+	"Mark 10:45",
+	"Luke 1:37",
+	"Luke 6:31",
+	"John 1:1",
+	"John 1:14",
+	"John 3:16",
+	"John 8:12",
+	"John 10:10",
+	"John 11:25-26",
+	"John 13:34-35",
+	"John 14:6",
+	"John 14:27",
+	"John 15:5",
+	"John 16:33",
+	"Acts 1:8",
+	"Romans 5:8",
+	"Romans 8:1",
+	"Romans 8:28",
+	"Romans 8:38-39",
+	"Romans 12:2",
+	"Romans 15:13",
+	"1 Corinthians 10:13",
+	"1 Corinthians 13:4-7",
+	"2 Corinthians 5:17",
+	"2 Corinthians 12:9",
+	"Galatians 2:20",
+	"Galatians 5:22-23",
+	"Galatians 6:9",
+	"Ephesians 2:8-9",
+	"Ephesians 2:10",
+	"Ephesians 4:32",
+	"Philippians 4:6-7",
+	"Philippians 4:8",
+	"Philippians 4:13",
+	"Colossians 3:23",
+	"1 Thessalonians 5:16-18",
+	"2 Timothy 1:7",
+	"2 Timothy 3:16-17",
+	"Hebrews 4:12",
+	"Hebrews 11:1",
+	"Hebrews 12:1-2",
+	"Hebrews 13:8",
+	"James 1:5",
+	"James 1:22",
+	"1 Peter 5:7",
+	"1 John 1:9",
+	"1 John 4:8",
+	"1 John 4:19",
+	"Revelation 21:4",
+	"Revelation 22:20",
+}
+
+// Pick returns the verse of the day for a date. Unless canon is set it comes
+// from Curated; otherwise any verse of the Bible may be chosen.
+func Pick(date time.Time, canon bool) *ref.Range {
+	seed := Seed(date)
+
+	if canon {
+		v := ref.VerseAt(int(seed % uint64(ref.TotalVerses())))
+		return &ref.Range{Start: *v, End: *v}
+	}
+
+	r, err := ref.ParseRange(Curated[seed%uint64(len(Curated))])
+	if err != nil {
+		// The list is checked by the tests
+		panic(err)
+	}
+
+	return r
+}
+
+// Seed returns the number the choice for a date is made from, which is the
+// FNV-1a hash of the date in DateFormat
+func Seed(date time.Time) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(date.Format(DateFormat)))
+	return h.Sum64()
+}
@@ -0,0 +1,52 @@
+package votd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtjm/bible/ref"
+)
+
+func TestCurated(t *testing.T) {
+	for _, s := range Curated {
+		if _, err := ref.ParseRange(s); err != nil {
+			t.Errorf("curated verse %q: %s", s, err)
+		}
+	}
+}
+
+func TestPick(t *testing.T) {
+	day, _ := time.Parse(DateFormat, "2026-10-18")
+	later := day.Add(23 * time.Hour)
+
+	for _, canon := range []bool{false, true} {
+		first := Pick(day, canon)
+		if again := Pick(later, canon); *again != *first {
+			t.Errorf("Pick(canon %v) changed during the day: %v then %v", canon, first, again)
+		}
+	}
+
+	// The same date must give the same verse everywhere, and in every version
+	// of the program
+	if got := Seed(day); got != 0x37c83b3a7a9f01cb {
+		t.Errorf("Seed(%v) -> %#x", day.Format(DateFormat), got)
+	}
+	if got := Pick(day, false).String(); got != "Romans 8:28" {
+		t.Errorf("Pick(%v, false) -> %v, wanted Romans 8:28", day.Format(DateFormat), got)
+	}
+	if got := Pick(day, true).String(); got != "Revelation 8:10" {
+		t.Errorf("Pick(%v, true) -> %v, wanted Revelation 8:10", day.Format(DateFormat), got)
+	}
+}
+
+func TestPickVaries(t *testing.T) {
+	day, _ := time.Parse(DateFormat, "2026-01-01")
+	seen := make(map[ref.Range]bool)
+	for i := 0; i < 30; i++ {
+		seen[*Pick(day.AddDate(0, 0, i), false)] = true
+	}
+
+	if len(seen) < 15 {
+		t.Errorf("only %d different verses in 30 days", len(seen))
+	}
+}